@@ -0,0 +1,216 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+const defaultApiEndpoint = "https://api.cloudns.net"
+
+const defaultHttpTimeout = 30
+
+// apiStatus is the envelope ClouDNS wraps around failed (and some successful) responses
+type apiStatus struct {
+	Status string `json:"status"`
+	Desc   string `json:"statusDescription"`
+}
+
+// apiConn holds the credentials and the HTTP client every request to the ClouDNS API is sent with
+type apiConn struct {
+	access   cloudns.Apiaccess
+	endpoint string
+	http     *http.Client
+}
+
+// httpSettings configures how the ClouDNS API is reached
+type httpSettings struct {
+	// timeout of a single request
+	timeout time.Duration
+	// proxyUrl overrides the proxy taken from HTTPS_PROXY / NO_PROXY
+	proxyUrl string
+	// caBundle is the path to a PEM file with certificates trusted in addition to the system roots
+	caBundle string
+	// debugLogging logs every request and response, see loggingTransport
+	debugLogging bool
+}
+
+func newApiConn(access cloudns.Apiaccess, endpoint string, settings httpSettings) (*apiConn, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if settings.proxyUrl != "" {
+		proxy, err := url.Parse(settings.proxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %v", settings.proxyUrl, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if settings.caBundle != "" {
+		pem, err := os.ReadFile(settings.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA bundle: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", settings.caBundle)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if settings.debugLogging {
+		roundTripper = &loggingTransport{next: transport, password: access.Authpassword}
+	}
+
+	return &apiConn{
+		access:   access,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		http: &http.Client{
+			Transport: roundTripper,
+			Timeout:   settings.timeout,
+		},
+	}, nil
+}
+
+// request calls an API endpoint and decodes the response body into out, which may be nil when the caller
+// only cares about the status. params is either a map or a struct with json tags, credentials are added to it.
+func (c *apiConn) request(ctx context.Context, path string, params interface{}, out interface{}) error {
+	payload, err := apiPayload(&c.access, params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "github.com/ClouDNS/terraform-provider-cloudns")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return apiHttpError{StatusCode: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return errors.New("empty response body")
+	}
+
+	var status apiStatus
+	if err := json.Unmarshal(data, &status); err == nil && status.Status != "" && status.Status != "Success" {
+		return apiStatusError(status.Desc)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error unmarshalling response from %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// apiPayload merges the credentials into the JSON representation of params
+func apiPayload(access *cloudns.Apiaccess, params interface{}) ([]byte, error) {
+	body := map[string]interface{}{}
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &body); err != nil {
+			return nil, err
+		}
+	}
+
+	delete(body, "auth-id")
+	delete(body, "sub-auth-id")
+	body["auth-password"] = access.Authpassword
+	if access.Authid != 0 {
+		body["auth-id"] = access.Authid
+	} else {
+		body["sub-auth-id"] = access.Subauthid
+	}
+
+	return json.Marshal(body)
+}
+
+// apiInt is a number ClouDNS returns either as a JSON number or as a string
+type apiInt int
+
+func (i *apiInt) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*i = apiInt(number)
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("cannot unmarshal %s into an integer", string(data))
+	}
+
+	if str == "" {
+		*i = 0
+		return nil
+	}
+
+	number, err := strconv.Atoi(str)
+	if err != nil {
+		return err
+	}
+	*i = apiInt(number)
+
+	return nil
+}
+
+// decodeApiList accepts both shapes ClouDNS uses for collections: a plain JSON array or an object keyed by ID
+func decodeApiList[T any](data json.RawMessage) ([]T, error) {
+	var list []T
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var keyed map[string]T
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return nil, err
+	}
+
+	for _, v := range keyed {
+		list = append(list, v)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,277 @@
+package cloudns
+
+import (
+	"strconv"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+// recordRequest is the request body of the endpoints adding and modifying records
+type recordRequest struct {
+	Domain             string  `json:"domain-name"`
+	RecordId           int     `json:"record-id,omitempty"`
+	Rtype              string  `json:"record-type,omitempty"`
+	TTL                int     `json:"ttl"`
+	Host               string  `json:"host"`
+	Record             string  `json:"record"`
+	Priority           *int    `json:"priority,omitempty"`
+	Weight             *int    `json:"weight,omitempty"`
+	Port               *int    `json:"port,omitempty"`
+	Frame              string  `json:"frame,omitempty"`
+	FrameTitle         string  `json:"frame-title,omitempty"`
+	FrameKeywords      string  `json:"frame-keywords,omitempty"`
+	FrameDescription   string  `json:"frame-description,omitempty"`
+	MobileMeta         int     `json:"mobile-meta,omitempty"`
+	SavePath           int     `json:"save-path,omitempty"`
+	RedirectType       int     `json:"redirect-type,omitempty"`
+	Mail               string  `json:"mail,omitempty"`
+	Txt                string  `json:"txt,omitempty"`
+	Algorithm          int     `json:"algorithm,omitempty"`
+	Fptype             int     `json:"fptype,omitempty"`
+	GeodnsLocation     string  `json:"geodns-location,omitempty"`
+	GeodnsCode         string  `json:"geodns-code,omitempty"`
+	CaaFlag            string  `json:"caa_flag,omitempty"`
+	CaaType            string  `json:"caa_type,omitempty"`
+	CaaValue           string  `json:"caa_value,omitempty"`
+	TlsaUsage          string  `json:"tlsa_usage,omitempty"`
+	TlsaSelector       string  `json:"tlsa_selector,omitempty"`
+	TlsaMatchingType   string  `json:"tlsa_matching_type,omitempty"`
+	SmimeaUsage        string  `json:"smimea-usage,omitempty"`
+	SmimeaSelector     string  `json:"smimea-selector,omitempty"`
+	SmimeaMatchingType string  `json:"smimea-matching-type,omitempty"`
+	KeyTag             int     `json:"key-tag,omitempty"`
+	DigestType         int     `json:"digest-type,omitempty"`
+	Order              string  `json:"order,omitempty"`
+	Pref               string  `json:"pref,omitempty"`
+	Flag               string  `json:"flag,omitempty"`
+	Params             string  `json:"params,omitempty"`
+	Regexp             string  `json:"regexp,omitempty"`
+	Replace            string  `json:"replace,omitempty"`
+	CertType           int     `json:"cert-type,omitempty"`
+	CertKeyTag         int     `json:"cert-key-tag,omitempty"`
+	CertAlgorithm      int     `json:"cert-algorithm,omitempty"`
+	LatDeg             float64 `json:"lat-deg,omitempty"`
+	LatMin             float64 `json:"lat-min,omitempty"`
+	LatSec             float64 `json:"lat-sec,omitempty"`
+	LatDir             string  `json:"lat-dir,omitempty"`
+	LongDeg            float64 `json:"long-deg,omitempty"`
+	LongMin            float64 `json:"long-min,omitempty"`
+	LongSec            float64 `json:"long-sec,omitempty"`
+	LongDir            string  `json:"long-dir,omitempty"`
+	Altitude           string  `json:"altitude,omitempty"`
+	Size               string  `json:"size,omitempty"`
+	HPrecision         string  `json:"h-precision,omitempty"`
+	VPrecision         string  `json:"v-precision,omitempty"`
+	CPU                string  `json:"cpu,omitempty"`
+	OS                 string  `json:"os,omitempty"`
+}
+
+// toRecordRequest only sends the fields relevant for the type of the record, the same way `cloudns-go` does
+func toRecordRequest(r cloudns.Record) recordRequest {
+	req := recordRequest{
+		Domain: r.Domain,
+		Rtype:  r.Rtype,
+		TTL:    r.TTL,
+		Host:   r.Host,
+		Record: r.Record,
+	}
+
+	switch r.Rtype {
+	case "MX":
+		req.Priority = &r.Priority
+	case "WR":
+		req.Frame = r.Frame
+		req.FrameTitle = r.FrameTitle
+		req.FrameKeywords = r.FrameKeywords
+		req.FrameDescription = r.FrameDescription
+		req.MobileMeta = r.MobileMeta
+		req.SavePath = r.SavePath
+		req.RedirectType = r.RedirectType
+	case "SRV":
+		req.Priority = &r.Priority
+		req.Weight = &r.Weight
+		req.Port = &r.Port
+	case "RP":
+		req.Mail = r.Mail
+		req.Txt = r.Txt
+	case "SSHFP":
+		req.Algorithm = r.Algorithm
+		req.Fptype = r.Fptype
+	case "NAPTR":
+		req.Flag = r.Flag
+		req.Order = r.Order
+		req.Pref = r.Pref
+		req.Params = r.Params
+		req.Regexp = r.Regexp
+		req.Replace = r.Replace
+	case "CAA":
+		req.CaaFlag = r.CaaFlag
+		req.CaaType = r.CaaType
+		req.CaaValue = r.CaaValue
+	case "TLSA":
+		req.TlsaUsage = r.TlsaUsage
+		req.TlsaSelector = r.TlsaSelector
+		req.TlsaMatchingType = r.TlsaMatchingType
+	case "DS":
+		req.KeyTag = r.KeyTag
+		req.Algorithm = r.Algorithm
+		req.DigestType = r.DigestType
+	case "CERT":
+		req.CertType = r.CertType
+		req.CertKeyTag = r.CertKeyTag
+		req.CertAlgorithm = r.CertAlgorithm
+	case "HINFO":
+		req.CPU = r.CPU
+		req.OS = r.OS
+	case "LOC":
+		req.LatDeg = r.LatDeg
+		req.LatMin = r.LatMin
+		req.LatSec = r.LatSec
+		req.LatDir = r.LatDir
+		req.LongDeg = r.LongDeg
+		req.LongMin = r.LongMin
+		req.LongSec = r.LongSec
+		req.LongDir = r.LongDir
+		req.Altitude = r.Altitude
+		req.Size = r.Size
+		req.HPrecision = r.HPrecision
+		req.VPrecision = r.VPrecision
+	case "SMIMEA":
+		req.SmimeaUsage = r.SmimeaUsage
+		req.SmimeaSelector = r.SmimeaSelector
+		req.SmimeaMatchingType = r.SmimeaMatchingType
+	}
+
+	req.GeodnsLocation = r.GeodnsLocation
+	req.GeodnsCode = r.GeodnsCode
+
+	return req
+}
+
+// apiRecord is a record as listed by ClouDNS
+type apiRecord struct {
+	ID                 string  `json:"id"`
+	Host               string  `json:"host"`
+	Rtype              string  `json:"type"`
+	TTL                apiInt  `json:"ttl"`
+	Record             string  `json:"record"`
+	Priority           apiInt  `json:"priority,omitempty"`
+	Weight             apiInt  `json:"weight,omitempty"`
+	Port               apiInt  `json:"port,omitempty"`
+	Frame              string  `json:"frame,omitempty"`
+	FrameTitle         string  `json:"frame-title,omitempty"`
+	FrameKeywords      string  `json:"frame-keywords,omitempty"`
+	FrameDescription   string  `json:"frame-description,omitempty"`
+	MobileMeta         apiInt  `json:"mobile-meta,omitempty"`
+	SavePath           apiInt  `json:"save-path,omitempty"`
+	RedirectType       apiInt  `json:"redirect-type,omitempty"`
+	Mail               string  `json:"mail,omitempty"`
+	Txt                string  `json:"txt,omitempty"`
+	Algorithm          apiInt  `json:"algorithm,omitempty"`
+	Fptype             apiInt  `json:"fptype,omitempty"`
+	Status             apiInt  `json:"status,omitempty"`
+	GeodnsLocation     string  `json:"geodns-location,omitempty"`
+	GeodnsCode         string  `json:"geodns-code,omitempty"`
+	CaaFlag            string  `json:"caa_flag,omitempty"`
+	CaaType            string  `json:"caa_type,omitempty"`
+	CaaValue           string  `json:"caa_value,omitempty"`
+	TlsaUsage          string  `json:"tlsa_usage,omitempty"`
+	TlsaSelector       string  `json:"tlsa_selector,omitempty"`
+	TlsaMatchingType   string  `json:"tlsa_matching_type,omitempty"`
+	SmimeaUsage        string  `json:"smimea-usage,omitempty"`
+	SmimeaSelector     string  `json:"smimea-selector,omitempty"`
+	SmimeaMatchingType string  `json:"smimea-matching-type,omitempty"`
+	KeyTag             apiInt  `json:"key-tag,omitempty"`
+	DigestType         apiInt  `json:"digest-type,omitempty"`
+	Order              string  `json:"order,omitempty"`
+	Pref               string  `json:"pref,omitempty"`
+	Flag               string  `json:"flag,omitempty"`
+	Params             string  `json:"params,omitempty"`
+	Regexp             string  `json:"regexp,omitempty"`
+	Replace            string  `json:"replace,omitempty"`
+	CertType           apiInt  `json:"cert-type,omitempty"`
+	CertKeyTag         apiInt  `json:"cert-key-tag,omitempty"`
+	CertAlgorithm      apiInt  `json:"cert-algorithm,omitempty"`
+	LatDeg             float64 `json:"lat-deg,omitempty"`
+	LatMin             float64 `json:"lat-min,omitempty"`
+	LatSec             float64 `json:"lat-sec,omitempty"`
+	LatDir             string  `json:"lat-dir,omitempty"`
+	LongDeg            float64 `json:"long-deg,omitempty"`
+	LongMin            float64 `json:"long-min,omitempty"`
+	LongSec            float64 `json:"long-sec,omitempty"`
+	LongDir            string  `json:"long-dir,omitempty"`
+	Altitude           string  `json:"altitude,omitempty"`
+	Size               string  `json:"size,omitempty"`
+	HPrecision         string  `json:"h-precision,omitempty"`
+	VPrecision         string  `json:"v-precision,omitempty"`
+	CPU                string  `json:"cpu,omitempty"`
+	OS                 string  `json:"os,omitempty"`
+}
+
+func (r apiRecord) toRecord(domain string) cloudns.Record {
+	return cloudns.Record{
+		Domain:             domain,
+		ID:                 r.ID,
+		Rtype:              r.Rtype,
+		Host:               r.Host,
+		TTL:                int(r.TTL),
+		Record:             r.Record,
+		Priority:           int(r.Priority),
+		Weight:             int(r.Weight),
+		Port:               int(r.Port),
+		Frame:              r.Frame,
+		FrameTitle:         r.FrameTitle,
+		FrameKeywords:      r.FrameKeywords,
+		FrameDescription:   r.FrameDescription,
+		MobileMeta:         int(r.MobileMeta),
+		SavePath:           int(r.SavePath),
+		RedirectType:       int(r.RedirectType),
+		Mail:               r.Mail,
+		Txt:                r.Txt,
+		Algorithm:          int(r.Algorithm),
+		Fptype:             int(r.Fptype),
+		Status:             int(r.Status),
+		GeodnsLocation:     r.GeodnsLocation,
+		GeodnsCode:         r.GeodnsCode,
+		CaaFlag:            r.CaaFlag,
+		CaaType:            r.CaaType,
+		CaaValue:           r.CaaValue,
+		TlsaUsage:          r.TlsaUsage,
+		TlsaSelector:       r.TlsaSelector,
+		TlsaMatchingType:   r.TlsaMatchingType,
+		SmimeaUsage:        r.SmimeaUsage,
+		SmimeaSelector:     r.SmimeaSelector,
+		SmimeaMatchingType: r.SmimeaMatchingType,
+		KeyTag:             int(r.KeyTag),
+		DigestType:         int(r.DigestType),
+		Order:              r.Order,
+		Pref:               r.Pref,
+		Flag:               r.Flag,
+		Params:             r.Params,
+		Regexp:             r.Regexp,
+		Replace:            r.Replace,
+		CertType:           int(r.CertType),
+		CertKeyTag:         int(r.CertKeyTag),
+		CertAlgorithm:      int(r.CertAlgorithm),
+		LatDeg:             r.LatDeg,
+		LatMin:             r.LatMin,
+		LatSec:             r.LatSec,
+		LatDir:             r.LatDir,
+		LongDeg:            r.LongDeg,
+		LongMin:            r.LongMin,
+		LongSec:            r.LongSec,
+		LongDir:            r.LongDir,
+		Altitude:           r.Altitude,
+		Size:               r.Size,
+		HPrecision:         r.HPrecision,
+		VPrecision:         r.VPrecision,
+		CPU:                r.CPU,
+		OS:                 r.OS,
+	}
+}
+
+// recordId converts the ID of a record for the endpoints which expect a number
+func recordId(r cloudns.Record) int {
+	id, _ := strconv.Atoi(r.ID)
+	return id
+}
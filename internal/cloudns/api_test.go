@@ -0,0 +1,151 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+func TestApiConnRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		switch r.URL.Path {
+		case "/dns/records.json":
+			if body["auth-id"] != 42.0 || body["auth-password"] != "secret" || body["domain-name"] != "example.com" {
+				t.Errorf("bad request body: %+v", body)
+			}
+			w.Write([]byte(`{"1": {"id": "1", "host": "www", "type": "A", "ttl": "3600", "record": "1.2.3.4"}}`))
+		case "/dns/delete.json":
+			w.Write([]byte(`{"status": "Failed", "statusDescription": "Zone example.com does not exist."}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	conn, err := newApiConn(cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}, server.URL+"/", httpSettings{timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newApiClient(conn, &countingLimiter{}, retryPolicy{})
+
+	records, err := client.ListRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Host != "www" || records[0].TTL != 3600 || records[0].Domain != "example.com" {
+		t.Fatalf("bad records: %+v", records)
+	}
+
+	_, err = client.DeleteZone(context.Background(), cloudns.Zone{Domain: "example.com"})
+	if !errors.Is(err, errNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	_, err = client.ListNameservers(context.Background())
+	if !errors.As(err, &apiHttpError{}) {
+		t.Fatalf("expected an HTTP error, got %v", err)
+	}
+}
+
+func TestNewApiConnSettings(t *testing.T) {
+	if _, err := newApiConn(cloudns.Apiaccess{}, defaultApiEndpoint, httpSettings{proxyUrl: "://proxy"}); err == nil {
+		t.Error("expected an invalid proxy URL to be rejected")
+	}
+
+	if _, err := newApiConn(cloudns.Apiaccess{}, defaultApiEndpoint, httpSettings{caBundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected a missing CA bundle to be rejected")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("no certificates here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newApiConn(cloudns.Apiaccess{}, defaultApiEndpoint, httpSettings{caBundle: empty}); err == nil {
+		t.Error("expected a CA bundle without certificates to be rejected")
+	}
+
+	conn, err := newApiConn(cloudns.Apiaccess{}, defaultApiEndpoint, httpSettings{timeout: 5 * time.Second, proxyUrl: "http://proxy.example.com:3128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.http.Timeout != 5*time.Second {
+		t.Errorf("bad timeout: %s", conn.http.Timeout)
+	}
+
+	proxy, err := conn.http.Transport.(*http.Transport).Proxy(httptest.NewRequest(http.MethodPost, defaultApiEndpoint, nil))
+	if err != nil || proxy.String() != "http://proxy.example.com:3128" {
+		t.Errorf("bad proxy: %v %v", proxy, err)
+	}
+}
+
+func TestFindRecordsPaginates(t *testing.T) {
+	const total = 2*recordPageSize + 50
+
+	var pages []float64
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["rows-per-page"] != float64(recordPageSize) {
+			t.Errorf("bad page size: %v", body["rows-per-page"])
+		}
+
+		page := body["page"].(float64)
+		pages = append(pages, page)
+		filters = append(filters, fmt.Sprintf("%v/%v", body["host"], body["type"]))
+
+		records := map[string]interface{}{}
+		for i := int(page-1) * recordPageSize; i < min(int(page)*recordPageSize, total); i++ {
+			id := strconv.Itoa(i + 1)
+			records[id] = map[string]string{"id": id, "host": "www", "type": "A", "ttl": "3600", "record": "1.2.3.4"}
+		}
+		// hosts are matched loosely by ClouDNS
+		if page == 1 && body["host"] == "www" {
+			records["0"] = map[string]string{"id": "0", "host": "www.sub", "type": "A", "ttl": "3600", "record": "1.2.3.4"}
+		}
+		json.NewEncoder(w).Encode(records)
+	}))
+	defer server.Close()
+
+	conn, err := newApiConn(cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}, server.URL, httpSettings{timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newApiClient(conn, &countingLimiter{}, retryPolicy{})
+
+	records, err := client.FindRecords(context.Background(), "example.com", recordFilter{host: "www", rtype: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != total {
+		t.Fatalf("expected %d records, got %d", total, len(records))
+	}
+	if !slices.Equal(pages, []float64{1, 2, 3}) || filters[0] != "www/A" {
+		t.Fatalf("bad requests: pages %v, filters %v", pages, filters)
+	}
+
+	if _, err := client.FindRecords(context.Background(), "example.com", recordFilter{rtype: "NS"}); err != nil {
+		t.Fatal(err)
+	}
+	if filters[len(filters)-1] != "<nil>/NS" {
+		t.Fatalf("expected only the type to be filtered on, got %v", filters[len(filters)-1])
+	}
+}
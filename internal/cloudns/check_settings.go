@@ -0,0 +1,213 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const checkTypePing = "1"
+
+// checkSchema holds the check and notification settings shared by failovers and monitoring checks
+func checkSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"monitoringregion": {
+			Description: "Monitoring region or country.",
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    false,
+		},
+		"checkperiod": {
+			Description: "Time-frame between each monitoring check.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"notificationmail": {
+			Description: "Email notifications settings.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"host": {
+			Description: "A host to query.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"port": {
+			Description: "A port to query.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"path": {
+			Description: "Path for the URL",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"content": {
+			Description: "Parameter required for Custom HTTP and Custom HTTPS check types",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"querytype": {
+			Description: "Parameter required for DNS check type. It must contain the record type (e.g. A).",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"queryresponse": {
+			Description: "Parameter required for DNS check type. You must fill in the response of the DNS server for this specific record.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"latencylimit": {
+			Description:      "Only for Ping monitoring checks. If the latency of the check (in milliseconds) is above the limit, the check will be marked as DOWN.",
+			Type:             schema.TypeInt,
+			Optional:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+		},
+		"timeout": {
+			Description:      "Only for Ping monitoring checks. Seconds to wait for a response. Must be between 1 and 5. Default value is 2.",
+			Type:             schema.TypeInt,
+			Optional:         true,
+			Computed:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(1, 5)),
+		},
+		"packetcount": {
+			Description:      "Only for Ping monitoring checks. The number of packets sent on each check.",
+			Type:             schema.TypeInt,
+			Optional:         true,
+			Computed:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+		},
+		"httprequesttype": {
+			Description: "Only for HTTP/S checks. The request type will be used for the check. The default value is GET. Possible values:",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+	}
+}
+
+// checkSettings is cloudns.CheckSettings as returned by the API, along with the settings `cloudns-go` does not model yet
+type checkSettings struct {
+	cloudns.CheckSettings
+	PacketCount apiInt `json:"packet_count,omitempty"`
+}
+
+// resourceCheckValidate rejects the Ping tunables for any other check type
+func resourceCheckValidate(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// a check type only known once applied may well be Ping
+	if !d.NewValueKnown("checktype") || d.Get("checktype").(string) == checkTypePing {
+		return nil
+	}
+
+	config := d.GetRawConfig()
+	for _, key := range []string{"latencylimit", "timeout", "packetcount"} {
+		if !config.IsNull() && !config.GetAttr(key).IsNull() {
+			return fmt.Errorf("%s is only supported for Ping checks (checktype %q)", key, checkTypePing)
+		}
+	}
+
+	return nil
+}
+
+func toApiCheckSettings(d *schema.ResourceData) (cloudns.CheckSettings, int) {
+	checkType := d.Get("checktype").(string)
+	portStr := d.Get("port").(string)
+
+	var port cloudns.CustomPort
+	if portInt, err := strconv.Atoi(portStr); err == nil {
+		port = cloudns.CustomPort(portInt)
+	} else {
+		if checkType == "4" || checkType == "6" {
+			port = 80
+		}
+
+		if checkType == "5" || checkType == "7" {
+			port = 443
+		}
+	}
+
+	settings := cloudns.CheckSettings{
+		Host:            d.Get("host").(string),
+		Port:            port,
+		Path:            d.Get("path").(string),
+		Content:         d.Get("content").(string),
+		QueryType:       d.Get("querytype").(string),
+		QueryResponse:   d.Get("queryresponse").(string),
+		LatencyLimit:    itoaOrEmpty(d.Get("latencylimit").(int)),
+		Timeout:         itoaOrEmpty(d.Get("timeout").(int)),
+		HttpRequestType: d.Get("httprequesttype").(string),
+	}
+
+	return settings, d.Get("packetcount").(int)
+}
+
+func updateCheckSettingsState(d *schema.ResourceData, settings cloudns.CheckSettings, packetCount int) error {
+	err := d.Set("host", settings.Host)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("port", strconv.Itoa(int(settings.Port))); err != nil {
+		return err
+	}
+
+	err = d.Set("path", settings.Path)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("content", settings.Content)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("querytype", settings.QueryType)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("queryresponse", settings.QueryResponse)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("latencylimit", atoiOrZero(settings.LatencyLimit))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("timeout", atoiOrZero(settings.Timeout))
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("packetcount", packetCount)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("httprequesttype", settings.HttpRequestType)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func itoaOrEmpty(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+func atoiOrZero(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return i
+}
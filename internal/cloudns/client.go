@@ -0,0 +1,616 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const notificationPageSize = 100
+
+// recordPageSize is the largest page ClouDNS returns records in
+const recordPageSize = 100
+
+// zonePageSize is the largest page ClouDNS returns zones in
+const zonePageSize = 100
+
+// cloudnsApi is everything the resources need from ClouDNS. apiClient implements it against the API,
+// tests swap in an implementation which keeps everything in memory.
+type cloudnsApi interface {
+	ListNameservers(ctx context.Context) ([]cloudns.Ns, error)
+
+	ListZones(ctx context.Context, search string) ([]cloudns.Zone, error)
+	CreateZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error)
+	ReadZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error)
+	DeleteZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error)
+
+	ListRecords(ctx context.Context, domain string) ([]cloudns.Record, error)
+	FindRecords(ctx context.Context, domain string, filter recordFilter) ([]cloudns.Record, error)
+	CreateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error)
+	UpdateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error)
+	DeleteRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error)
+
+	CreateFailover(ctx context.Context, failover apiFailover) (apiFailover, error)
+	ReadFailover(ctx context.Context, failover apiFailover) (apiFailover, error)
+	UpdateFailover(ctx context.Context, failover apiFailover) (apiFailover, error)
+	DeleteFailover(ctx context.Context, failover apiFailover) error
+	ListFailoverNotifications(ctx context.Context, domain string, recordId string) ([]failoverNotification, error)
+	AddFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error
+	DeleteFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error
+	ReadFailoverWebhook(ctx context.Context, domain string, recordId string, event string) (failoverWebhook, error)
+	SetFailoverWebhook(ctx context.Context, domain string, recordId string, event string, webhook failoverWebhook) error
+	DeleteFailoverWebhook(ctx context.Context, domain string, recordId string, event string) error
+
+	CreateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error)
+	ReadMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error)
+	UpdateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error)
+	DeleteMonitoringCheck(ctx context.Context, check monitoringCheck) error
+
+	ReadOrCreateDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error)
+	ReadDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error)
+	ChangeDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error)
+	DeleteDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) error
+}
+
+var _ cloudnsApi = (*apiClient)(nil)
+
+// apiClient is how resources talk to ClouDNS. Every call made through it observes the rate
+// limit, checks the context, is retried on transient failures and is logged.
+type apiClient struct {
+	conn    *apiConn
+	limiter rateLimiter
+	retry   retryPolicy
+	zones   *zoneCache
+	// zoneWrites serializes changes to the content of a zone, nil if they may run in parallel
+	zoneWrites *zoneLocks
+}
+
+func newApiClient(conn *apiConn, limiter rateLimiter, retry retryPolicy) *apiClient {
+	return &apiClient{
+		conn:    conn,
+		limiter: limiter,
+		retry:   retry,
+		zones:   newZoneCache(zoneCacheTTL),
+	}
+}
+
+// call runs a single API call named op, every attempt counts against the rate limit
+func (c *apiClient) call(ctx context.Context, op string, fn func(ctx context.Context, conn *apiConn) error) error {
+	return c.callWith(ctx, c.retry, op, fn)
+}
+
+// callWith runs a single API call named op, retried as the policy says
+func (c *apiClient) callWith(ctx context.Context, policy retryPolicy, op string, fn func(ctx context.Context, conn *apiConn) error) error {
+	return policy.run(ctx, op, func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			// rate.Limiter gives up right away when the wait would outlast the deadline
+			if _, ok := ctx.Deadline(); ok && ctx.Err() == nil {
+				return fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+			}
+			return err
+		}
+
+		tflog.Debug(ctx, "ClouDNS API call", map[string]interface{}{"operation": op})
+
+		err := toApiError(fn(ctx, c.conn))
+		if err != nil {
+			tflog.Debug(ctx, "ClouDNS API call failed", map[string]interface{}{"operation": op, "error": err.Error()})
+		}
+
+		if feedback, ok := c.limiter.(rateFeedback); ok {
+			if isThrottlingError(err) {
+				feedback.throttled(ctx)
+			} else if err == nil {
+				feedback.succeeded(ctx)
+			}
+		}
+
+		return err
+	})
+}
+
+// request calls a single API endpoint, see apiConn.request
+func (c *apiClient) request(ctx context.Context, path string, params interface{}, out interface{}) error {
+	return c.call(ctx, path, func(ctx context.Context, conn *apiConn) error {
+		return conn.request(ctx, path, params, out)
+	})
+}
+
+// write calls an API endpoint which must not be repeated once it was processed, see retryPolicy.nonIdempotent
+func (c *apiClient) write(ctx context.Context, path string, params interface{}, out interface{}) error {
+	return c.callWith(ctx, c.retry.nonIdempotent(), path, func(ctx context.Context, conn *apiConn) error {
+		return conn.request(ctx, path, params, out)
+	})
+}
+
+// remove calls an API endpoint deleting an object. Deleting is retried like any other call, when a retry finds the
+// object gone though, the attempt before it deleted the object and its response was lost, so that is no failure.
+func (c *apiClient) remove(ctx context.Context, path string, params interface{}) error {
+	attempts := 0
+	return c.call(ctx, path, func(ctx context.Context, conn *apiConn) error {
+		attempts++
+		err := conn.request(ctx, path, params, nil)
+		if attempts > 1 && errors.Is(toApiError(err), errNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Login checks the credentials, the IP address the request comes from and the state of the API user
+func (c *apiClient) Login(ctx context.Context) error {
+	return c.request(ctx, "/dns/login.json", nil, nil)
+}
+
+// ZONES
+
+func (c *apiClient) ListNameservers(ctx context.Context) ([]cloudns.Ns, error) {
+	var listed []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	err := c.request(ctx, "/dns/available-name-servers.json", map[string]interface{}{
+		"detailed-info": 1,
+	}, &listed)
+	if err != nil {
+		return nil, err
+	}
+
+	var nsList []cloudns.Ns
+	for _, ns := range listed {
+		nsList = append(nsList, cloudns.Ns{Type: ns.Type, Name: ns.Name})
+	}
+
+	return nsList, nil
+}
+
+func (c *apiClient) CreateZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	unlock, err := c.zoneWrites.lock(ctx, zone.Domain)
+	if err != nil {
+		return zone, err
+	}
+	defer unlock()
+	defer c.zones.invalidate(zone.Domain)
+
+	return zone, c.write(ctx, "/dns/register.json", zone, nil)
+}
+
+// ListZones returns the zones of the account whose name contains search, fetched page by page
+func (c *apiClient) ListZones(ctx context.Context, search string) ([]cloudns.Zone, error) {
+	var zones []cloudns.Zone
+
+	for page := 1; ; page++ {
+		params := map[string]interface{}{
+			"page":          page,
+			"rows-per-page": zonePageSize,
+		}
+		if search != "" {
+			params["search"] = search
+		}
+
+		var raw json.RawMessage
+		if err := c.request(ctx, "/dns/list-zones.json", params, &raw); err != nil {
+			return nil, err
+		}
+
+		listed, err := decodeApiList[struct {
+			Domain string `json:"name"`
+			Ztype  string `json:"type"`
+			Ns     string `json:"ns,omitempty"`
+		}](raw)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling zones: %v", err)
+		}
+
+		for _, z := range listed {
+			zones = append(zones, cloudns.Zone{
+				Domain: z.Domain,
+				Ztype:  z.Ztype,
+				Ns:     []string{z.Ns},
+			})
+		}
+
+		if len(listed) < zonePageSize {
+			break
+		}
+	}
+
+	return zones, nil
+}
+
+// ReadZone looks the zone up by its name, the API has no endpoint returning a single zone
+func (c *apiClient) ReadZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	listed, err := c.ListZones(ctx, zone.Domain)
+	if err != nil {
+		return zone, err
+	}
+
+	for _, z := range listed {
+		if z.Domain == zone.Domain {
+			return z, nil
+		}
+	}
+
+	return zone, fmt.Errorf("zone %s: %w", zone.Domain, errNotFound)
+}
+
+func (c *apiClient) DeleteZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	unlock, err := c.zoneWrites.lock(ctx, zone.Domain)
+	if err != nil {
+		return zone, err
+	}
+	defer unlock()
+	defer c.zones.invalidate(zone.Domain)
+
+	return zone, c.remove(ctx, "/dns/delete.json", map[string]interface{}{
+		"domain-name": zone.Domain,
+	})
+}
+
+// RECORDS
+
+// recordFilter narrows a listing of records down on the ClouDNS side, empty fields match any record.
+// ClouDNS can't filter on the apex of a zone, as an empty host means no filter.
+type recordFilter struct {
+	host  string
+	rtype string
+}
+
+func (f recordFilter) matches(record cloudns.Record) bool {
+	return (f.host == "" || f.host == record.Host) && (f.rtype == "" || f.rtype == record.Rtype)
+}
+
+// ListRecords returns all records of a zone, listings are shared between all resources reading the same zone
+func (c *apiClient) ListRecords(ctx context.Context, domain string) ([]cloudns.Record, error) {
+	return c.FindRecords(ctx, domain, recordFilter{})
+}
+
+// FindRecords returns the records of a zone matching filter, fetched page by page
+func (c *apiClient) FindRecords(ctx context.Context, domain string, filter recordFilter) ([]cloudns.Record, error) {
+	return c.zones.get(ctx, domain, filter, func() ([]cloudns.Record, error) {
+		var records []cloudns.Record
+
+		for page := 1; ; page++ {
+			params := map[string]interface{}{
+				"domain-name":   domain,
+				"page":          page,
+				"rows-per-page": recordPageSize,
+			}
+			if filter.host != "" {
+				params["host"] = filter.host
+			}
+			if filter.rtype != "" {
+				params["type"] = filter.rtype
+			}
+
+			var raw json.RawMessage
+			if err := c.request(ctx, "/dns/records.json", params, &raw); err != nil {
+				return nil, err
+			}
+
+			listed, err := decodeApiList[apiRecord](raw)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling records: %v", err)
+			}
+
+			for _, r := range listed {
+				record := r.toRecord(domain)
+				// ClouDNS may match hosts loosely, only exact matches are wanted
+				if filter.matches(record) {
+					records = append(records, record)
+				}
+			}
+
+			if len(listed) < recordPageSize {
+				break
+			}
+		}
+
+		return records, nil
+	})
+}
+
+func (c *apiClient) CreateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	unlock, err := c.zoneWrites.lock(ctx, record.Domain)
+	if err != nil {
+		return record, err
+	}
+	defer unlock()
+	defer c.zones.invalidate(record.Domain)
+
+	var created struct {
+		Data struct {
+			ID apiInt `json:"id"`
+		} `json:"data"`
+	}
+	attempts := 0
+	err = c.call(ctx, "/dns/add-record.json", func(ctx context.Context, conn *apiConn) error {
+		attempts++
+		if attempts > 1 {
+			// the previous attempt may have added the record before its response was lost, it is not added twice
+			added, err := c.findAddedRecord(ctx, record)
+			if err != nil || added != "" {
+				record.ID = added
+				return err
+			}
+		}
+
+		err := conn.request(ctx, "/dns/add-record.json", toRecordRequest(record), &created)
+		if err == nil {
+			record.ID = strconv.Itoa(int(created.Data.ID))
+		}
+		return err
+	})
+	return record, err
+}
+
+// findAddedRecord returns the ID of the record with the host, type and value of record, empty when there is none
+func (c *apiClient) findAddedRecord(ctx context.Context, record cloudns.Record) (string, error) {
+	c.zones.invalidate(record.Domain)
+
+	records, err := c.FindRecords(ctx, record.Domain, recordFilter{host: record.Host, rtype: record.Rtype})
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range records {
+		if r.Host == record.Host && r.Record == record.Record {
+			return r.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *apiClient) UpdateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	unlock, err := c.zoneWrites.lock(ctx, record.Domain)
+	if err != nil {
+		return record, err
+	}
+	defer unlock()
+	defer c.zones.invalidate(record.Domain)
+
+	req := toRecordRequest(record)
+	req.RecordId = recordId(record)
+	// the type of a record can't be modified
+	req.Rtype = ""
+
+	// the record is given all of its values, not changes to them, so repeating an update which was processed
+	// already leaves the record as it is
+	return record, c.request(ctx, "/dns/mod-record.json", req, nil)
+}
+
+func (c *apiClient) DeleteRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	unlock, err := c.zoneWrites.lock(ctx, record.Domain)
+	if err != nil {
+		return record, err
+	}
+	defer unlock()
+	defer c.zones.invalidate(record.Domain)
+
+	return record, c.remove(ctx, "/dns/delete-record.json", map[string]interface{}{
+		"domain-name": record.Domain,
+		"record-id":   recordId(record),
+	})
+}
+
+// FAILOVERS
+
+func (c *apiClient) CreateFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	unlock, err := c.zoneWrites.lock(ctx, failover.Domain)
+	if err != nil {
+		return failover, err
+	}
+	defer unlock()
+
+	err = c.callWith(ctx, c.retry.nonIdempotent(), "failover.create", func(ctx context.Context, conn *apiConn) (err error) {
+		failover, err = failover.Create(ctx, conn)
+		return err
+	})
+	return failover, err
+}
+
+func (c *apiClient) ReadFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	err := c.call(ctx, "failover.read", func(ctx context.Context, conn *apiConn) (err error) {
+		failover, err = failover.Read(ctx, conn)
+		return err
+	})
+	return failover, err
+}
+
+func (c *apiClient) UpdateFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	unlock, err := c.zoneWrites.lock(ctx, failover.Domain)
+	if err != nil {
+		return failover, err
+	}
+	defer unlock()
+
+	err = c.call(ctx, "failover.update", func(ctx context.Context, conn *apiConn) (err error) {
+		failover, err = failover.Update(ctx, conn)
+		return err
+	})
+	return failover, err
+}
+
+func (c *apiClient) DeleteFailover(ctx context.Context, failover apiFailover) error {
+	unlock, err := c.zoneWrites.lock(ctx, failover.Domain)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return c.remove(ctx, "/dns/failover-deactivate.json", map[string]interface{}{
+		"domain-name": failover.Domain,
+		"record-id":   failover.RecordId,
+	})
+}
+
+func (c *apiClient) ListFailoverNotifications(ctx context.Context, domain string, recordId string) ([]failoverNotification, error) {
+	var notifications []failoverNotification
+
+	for page := 1; ; page++ {
+		var raw json.RawMessage
+		err := c.request(ctx, "/dns/failover-notifications-list.json", map[string]interface{}{
+			"domain-name":   domain,
+			"record-id":     recordId,
+			"page":          page,
+			"rows-per-page": notificationPageSize,
+		}, &raw)
+		if err != nil {
+			return nil, err
+		}
+
+		pageNotifications, err := decodeApiList[failoverNotification](raw)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling failover notifications: %v", err)
+		}
+
+		notifications = append(notifications, pageNotifications...)
+		if len(pageNotifications) < notificationPageSize {
+			break
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		// the IDs are numbers, so "10" comes after "9"
+		return atoiOrZero(notifications[i].ID) < atoiOrZero(notifications[j].ID)
+	})
+
+	return notifications, nil
+}
+
+func (c *apiClient) AddFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error {
+	return c.write(ctx, "/dns/failover-notifications-add.json", map[string]interface{}{
+		"domain-name": domain,
+		"record-id":   recordId,
+		"type":        notification.Type,
+		"value":       notification.Value,
+	}, nil)
+}
+
+func (c *apiClient) DeleteFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error {
+	return c.remove(ctx, "/dns/failover-notifications-delete.json", map[string]interface{}{
+		"domain-name":     domain,
+		"record-id":       recordId,
+		"notification-id": notification.ID,
+	})
+}
+
+// The /dns/failover-webhook-get, -set and -delete endpoints are not used by cloudns-go, which only covers the
+// failover settings, and neither a reference in the ClouDNS API documentation nor recorded responses back them yet.
+// They are only exercised against the fake API, so their paths and parameters need checking against ClouDNS before
+// a release relies on them.
+func (c *apiClient) ReadFailoverWebhook(ctx context.Context, domain string, recordId string, event string) (failoverWebhook, error) {
+	var webhook failoverWebhook
+	err := c.request(ctx, "/dns/failover-webhook-get.json", map[string]interface{}{
+		"domain-name": domain,
+		"record-id":   recordId,
+		"event":       event,
+	}, &webhook)
+	return webhook, err
+}
+
+func (c *apiClient) SetFailoverWebhook(ctx context.Context, domain string, recordId string, event string, webhook failoverWebhook) error {
+	return c.request(ctx, "/dns/failover-webhook-set.json", map[string]interface{}{
+		"domain-name": domain,
+		"record-id":   recordId,
+		"event":       event,
+		"url":         webhook.Url,
+		"method":      webhook.Method,
+		"payload":     webhook.Payload,
+	}, nil)
+}
+
+func (c *apiClient) DeleteFailoverWebhook(ctx context.Context, domain string, recordId string, event string) error {
+	return c.remove(ctx, "/dns/failover-webhook-delete.json", map[string]interface{}{
+		"domain-name": domain,
+		"record-id":   recordId,
+		"event":       event,
+	})
+}
+
+// MONITORING
+
+func (c *apiClient) CreateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	err := c.callWith(ctx, c.retry.nonIdempotent(), "monitoring.create", func(ctx context.Context, conn *apiConn) (err error) {
+		check, err = check.Create(ctx, conn)
+		return err
+	})
+	return check, err
+}
+
+func (c *apiClient) ReadMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	err := c.call(ctx, "monitoring.read", func(ctx context.Context, conn *apiConn) (err error) {
+		check, err = check.Read(ctx, conn)
+		return err
+	})
+	return check, err
+}
+
+func (c *apiClient) UpdateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	err := c.call(ctx, "monitoring.update", func(ctx context.Context, conn *apiConn) (err error) {
+		check, err = check.Update(ctx, conn)
+		return err
+	})
+	return check, err
+}
+
+func (c *apiClient) DeleteMonitoringCheck(ctx context.Context, check monitoringCheck) error {
+	return c.call(ctx, "monitoring.delete", func(ctx context.Context, conn *apiConn) error {
+		_, err := check.Delete(ctx, conn)
+		return err
+	})
+}
+
+// DYNAMIC URLS
+
+func (c *apiClient) ReadOrCreateDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	return c.dynamicUrlRequest(ctx, "/dns/get-dynamic-url.json", dynUrl)
+}
+
+// ReadDynamicUrl returns the current dynamic URL of a record, unlike ReadOrCreateDynamicUrl it never creates one.
+// Unlike the other dynamic URL endpoints, /dns/list-dynamic-url.json is not used by cloudns-go, and neither a
+// reference in the ClouDNS API documentation nor a recorded response back it yet. It is only exercised against the
+// fake API, so its path and response need checking against ClouDNS before a release relies on it.
+func (c *apiClient) ReadDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	resp, err := c.dynamicUrlRequest(ctx, "/dns/list-dynamic-url.json", dynUrl)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.Url == "" {
+		return resp, fmt.Errorf("dynamic URL for record %s: %w", dynUrl.RecordId, errNotFound)
+	}
+
+	return resp, nil
+}
+
+func (c *apiClient) ChangeDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	return c.dynamicUrlRequest(ctx, "/dns/change-dynamic-url.json", dynUrl)
+}
+
+func (c *apiClient) DeleteDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) error {
+	return c.remove(ctx, "/dns/disable-dynamic-url.json", dynUrl)
+}
+
+func (c *apiClient) dynamicUrlRequest(ctx context.Context, path string, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	var resp cloudns.DynamicUrlResponse
+	err := c.request(ctx, path, dynUrl, &resp)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Domain = dynUrl.Domain
+	resp.RecordId = dynUrl.RecordId
+
+	return resp, nil
+}
@@ -0,0 +1,313 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+type countingLimiter struct {
+	taken int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.taken++
+	return nil
+}
+
+func TestApiClientCallTakesLimiter(t *testing.T) {
+	limiter := &countingLimiter{}
+	client := newApiClient(&apiConn{access: cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}}, limiter, retryPolicy{})
+
+	failure := errors.New("failure")
+	for i := 0; i < 3; i++ {
+		err := client.call(context.Background(), "test", func(ctx context.Context, conn *apiConn) error {
+			if conn.access.Authid != 42 {
+				t.Errorf("bad auth id: %d", conn.access.Authid)
+			}
+			return failure
+		})
+		if err != failure {
+			t.Fatalf("expected error to be passed through, got: %v", err)
+		}
+	}
+
+	if limiter.taken != 3 {
+		t.Fatalf("expected the limiter to be taken 3 times, got: %d", limiter.taken)
+	}
+}
+
+func TestApiClientCallCancelled(t *testing.T) {
+	limiter := &countingLimiter{}
+	client := newApiClient(&apiConn{}, limiter, retryPolicy{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.call(ctx, "test", func(ctx context.Context, conn *apiConn) error {
+		t.Fatal("expected no call once the context is cancelled")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+
+	if limiter.taken != 0 {
+		t.Fatalf("expected the limiter not to be taken, got: %d", limiter.taken)
+	}
+}
+
+type blockingLimiter struct {
+	release chan struct{}
+}
+
+func (l *blockingLimiter) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.release:
+		return nil
+	}
+}
+
+func TestApiClientCallCancelledWhileWaiting(t *testing.T) {
+	limiter := &blockingLimiter{release: make(chan struct{})}
+	defer close(limiter.release)
+
+	client := newApiClient(&apiConn{}, limiter, retryPolicy{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := client.call(ctx, "test", func(ctx context.Context, conn *apiConn) error {
+		t.Error("expected no call while the limiter blocks")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait for the limiter to be aborted, got: %v", err)
+	}
+}
+
+func TestApiClientCallDeadlineBeforeLimit(t *testing.T) {
+	client := newApiClient(&apiConn{}, newRateLimiter(1, false), retryPolicy{})
+	noop := func(ctx context.Context, conn *apiConn) error { return nil }
+
+	if err := client.call(context.Background(), "test", noop); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := client.call(ctx, "test", noop); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the call to fail without waiting for the limiter, took %s", elapsed)
+	}
+}
+
+func TestApiClientCallCancelledInFlight(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	conn, err := newApiConn(cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}, server.URL, httpSettings{timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newApiClient(conn, newAdaptiveLimiter(10), newRetryPolicy(defaultMaxRetries, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.ListRecords(ctx, "example.com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to be aborted, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the request to be aborted right away, took %s", elapsed)
+	}
+}
+
+func TestApiClientRetriesRequestTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	conn, err := newApiConn(cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}, server.URL, httpSettings{timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newApiClient(conn, newAdaptiveLimiter(10), retryPolicy{maxRetries: 3, maxElapsed: time.Minute, baseDelay: time.Millisecond, maxDelay: time.Millisecond})
+
+	if _, err := client.ListRecords(context.Background(), "example.com"); err != nil {
+		t.Fatalf("expected the request which timed out to be retried, got: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+// dropAfterWrite serves the fake API, except the first call to path is processed and then left without response
+func dropAfterWrite(t *testing.T, api *fakeApi, path string) string {
+	var dropped atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path || !dropped.CompareAndSwap(false, true) {
+			api.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+
+		api.Config.Handler.ServeHTTP(httptest.NewRecorder(), r)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestApiClientCreateRecordNotAddedTwice(t *testing.T) {
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	client := fakeApiClientConfig(t, dropAfterWrite(t, api, "/dns/add-record.json")).client
+
+	record, err := client.CreateRecord(context.Background(), cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatalf("expected the record added by the dropped call to be found, got: %v", err)
+	}
+
+	var added []string
+	for _, r := range api.Records("example.com") {
+		if r.str("host") == "www" {
+			added = append(added, r.str("id"))
+		}
+	}
+	if len(added) != 1 || added[0] != record.ID {
+		t.Fatalf("expected the record to be added once as %s, got %v", record.ID, added)
+	}
+}
+
+func TestApiClientNotificationNotAddedTwice(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	client := fakeApiClientConfig(t, dropAfterWrite(t, api, "/dns/failover-notifications-add.json")).client
+
+	record, err := client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failover := apiFailover{}
+	failover.Domain = "example.com"
+	failover.RecordId = record.ID
+	failover.FailoverType = checkTypePing
+	failover.MainIP = "1.2.3.4"
+	if _, err := client.CreateFailover(ctx, failover); err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.AddFailoverNotification(ctx, "example.com", record.ID, failoverNotification{Type: "mail", Value: "admin@example.com"})
+	if err == nil {
+		t.Fatal("expected the dropped call to fail rather than be repeated")
+	}
+
+	notifications, err := client.ListFailoverNotifications(ctx, "example.com", record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("expected the notification to be added once, got %+v", notifications)
+	}
+}
+
+func TestApiClientDeleteResponseLost(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	api.AddZone("example.net", "master")
+	endpoint := dropAfterWrite(t, api, "/dns/delete-record.json")
+	client := fakeApiClientConfig(t, endpoint).client
+
+	record, err := client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.DeleteRecord(ctx, record); err != nil {
+		t.Fatalf("expected the record deleted by the dropped call to be taken for deleted, got: %v", err)
+	}
+	for _, r := range api.Records("example.com") {
+		if r.str("id") == record.ID {
+			t.Fatal("expected the record to be deleted")
+		}
+	}
+
+	// without a lost response, deleting what is not there still fails
+	if _, err := client.DeleteRecord(ctx, record); !errors.Is(err, errNotFound) {
+		t.Fatalf("expected a not found error, got: %v", err)
+	}
+
+	client = fakeApiClientConfig(t, dropAfterWrite(t, api, "/dns/delete.json")).client
+	if _, err := client.DeleteZone(ctx, cloudns.Zone{Domain: "example.net"}); err != nil {
+		t.Fatalf("expected the zone deleted by the dropped call to be taken for deleted, got: %v", err)
+	}
+	if _, err := client.ReadZone(ctx, cloudns.Zone{Domain: "example.net"}); !errors.Is(err, errNotFound) {
+		t.Fatalf("expected the zone to be deleted, got: %v", err)
+	}
+}
+
+func TestApiClientListFailoverNotificationsByNumericId(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	client := api.ClientConfig(t).client
+
+	record, err := client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failover := apiFailover{}
+	failover.Domain = "example.com"
+	failover.RecordId = record.ID
+	failover.FailoverType = checkTypePing
+	failover.MainIP = "1.2.3.4"
+	if _, err := client.CreateFailover(ctx, failover); err != nil {
+		t.Fatal(err)
+	}
+
+	// the notifications are given the IDs 9 and 10
+	api.nextId = 8
+	for _, value := range []string{"first@example.com", "second@example.com"} {
+		if err := client.AddFailoverNotification(ctx, "example.com", record.ID, failoverNotification{Type: "mail", Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notifications, err := client.ListFailoverNotifications(ctx, "example.com", record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 2 || notifications[0].ID != "9" || notifications[1].ID != "10" {
+		t.Fatalf("expected the notifications in the order of their IDs, got %+v", notifications)
+	}
+}
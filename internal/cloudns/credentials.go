@@ -0,0 +1,265 @@
+package cloudns
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const defaultProfile = "default"
+
+// defaultCredentialsFile is relative to the home directory of the user running Terraform
+var defaultCredentialsFile = filepath.Join(".cloudns", "credentials")
+
+// credentials are the auth-id or sub-auth-id along with the password used for every API call
+type credentials struct {
+	authId    int
+	subAuthId int
+	password  string
+}
+
+// merge fills in what c is missing from other, the auth IDs are taken from a single source only
+func (c credentials) merge(other credentials) credentials {
+	if c.authId == 0 && c.subAuthId == 0 {
+		c.authId = other.authId
+		c.subAuthId = other.subAuthId
+	}
+	if c.password == "" {
+		c.password = other.password
+	}
+	return c
+}
+
+func (c credentials) complete() bool {
+	return (c.authId != 0 || c.subAuthId != 0) && c.password != ""
+}
+
+// resolveCredentials applies the precedence of the credential sources:
+//  1. `auth_id`, `sub_auth_id` and `password` in the provider block
+//  2. the profile of the credentials file, if `profile` or `credentials_file` is set (the CLOUDNS_* credential variables are ignored then)
+//  3. the CLOUDNS_AUTH_ID, CLOUDNS_SUB_AUTH_ID and CLOUDNS_PASSWORD environment variables
+//  4. the default profile of ~/.cloudns/credentials, if that file exists
+func resolveCredentials(d *schema.ResourceData) (credentials, error) {
+	authId, err := parseAuthId("auth_id", d.Get("auth_id").(string))
+	if err != nil {
+		return credentials{}, err
+	}
+	subAuthId, err := parseAuthId("sub_auth_id", d.Get("sub_auth_id").(string))
+	if err != nil {
+		return credentials{}, err
+	}
+
+	creds := credentials{
+		authId:    authId,
+		subAuthId: subAuthId,
+		password:  d.Get("password").(string),
+	}
+
+	path := d.Get("credentials_file").(string)
+	profile := d.Get("profile").(string)
+
+	if path != "" || profile != "" {
+		if profile == "" {
+			profile = defaultProfile
+		}
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return creds, fmt.Errorf("unable to locate the credentials file for profile %q: %w", profile, err)
+			}
+			path = filepath.Join(home, defaultCredentialsFile)
+		}
+
+		fromFile, err := readCredentialsProfile(path, profile)
+		if err != nil {
+			return creds, err
+		}
+		return creds.merge(fromFile), nil
+	}
+
+	fromEnv, err := envCredentials()
+	if err != nil {
+		return creds, err
+	}
+	creds = creds.merge(fromEnv)
+
+	if !creds.complete() {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return creds, nil
+		}
+		fromFile, err := readCredentialsProfile(filepath.Join(home, defaultCredentialsFile), defaultProfile)
+		if errors.Is(err, os.ErrNotExist) {
+			return creds, nil
+		}
+		if err != nil {
+			return creds, err
+		}
+		creds = creds.merge(fromFile)
+	}
+
+	return creds, nil
+}
+
+func envCredentials() (credentials, error) {
+	authId, err := parseAuthId(EnvVarAuthId, os.Getenv(EnvVarAuthId))
+	if err != nil {
+		return credentials{}, err
+	}
+	subAuthId, err := parseAuthId(EnvVarSubAuthId, os.Getenv(EnvVarSubAuthId))
+	if err != nil {
+		return credentials{}, err
+	}
+
+	return credentials{
+		authId:    authId,
+		subAuthId: subAuthId,
+		password:  os.Getenv(EnvVarPassword),
+	}, nil
+}
+
+// parseAuthId accepts IDs surrounded by whitespace, as e.g. read from a secret manager with a trailing newline.
+// The value is not part of the error as the IDs are sensitive.
+func parseAuthId(name, value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return id, nil
+}
+
+func validateAuthId(v interface{}, k string) ([]string, []error) {
+	if _, err := parseAuthId(k, v.(string)); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
+
+// readCredentialsProfile reads a profile from an INI or JSON credentials file, e.g.
+//
+//	[default]
+//	auth_id  = 1234
+//	password = verysecret
+//
+// or
+//
+//	{"default": {"auth_id": 1234, "password": "verysecret"}}
+func readCredentialsProfile(path, profile string) (credentials, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return credentials{}, fmt.Errorf("unable to read the credentials file: %w", err)
+	}
+
+	var profiles map[string]map[string]string
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		profiles, err = parseJsonProfiles(trimmed)
+	} else {
+		profiles, err = parseIniProfiles(content)
+	}
+	if err != nil {
+		return credentials{}, fmt.Errorf("unable to parse the credentials file %s: %w", path, err)
+	}
+
+	values, ok := profiles[profile]
+	if !ok {
+		return credentials{}, fmt.Errorf("profile %q not found in the credentials file %s", profile, path)
+	}
+
+	for key := range values {
+		switch key {
+		case "auth_id", "sub_auth_id", "password":
+		default:
+			return credentials{}, fmt.Errorf("unknown setting %q in profile %q of the credentials file %s", key, profile, path)
+		}
+	}
+
+	authId, err := parseAuthId("auth_id", values["auth_id"])
+	if err != nil {
+		return credentials{}, fmt.Errorf("profile %q: %w", profile, err)
+	}
+	subAuthId, err := parseAuthId("sub_auth_id", values["sub_auth_id"])
+	if err != nil {
+		return credentials{}, fmt.Errorf("profile %q: %w", profile, err)
+	}
+
+	return credentials{
+		authId:    authId,
+		subAuthId: subAuthId,
+		password:  values["password"],
+	}, nil
+}
+
+func parseJsonProfiles(content []byte) (map[string]map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var raw map[string]map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]map[string]string, len(raw))
+	for name, settings := range raw {
+		profiles[name] = make(map[string]string, len(settings))
+		for key, value := range settings {
+			switch v := value.(type) {
+			case string:
+				profiles[name][key] = v
+			case json.Number:
+				profiles[name][key] = v.String()
+			default:
+				return nil, fmt.Errorf("profile %q: %s must be a string or a number", name, key)
+			}
+		}
+	}
+
+	return profiles, nil
+}
+
+func parseIniProfiles(content []byte) (map[string]map[string]string, error) {
+	profiles := map[string]map[string]string{}
+	var section map[string]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = map[string]string{}
+			}
+			section = profiles[name]
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a [profile] or a key = value pair", n)
+			}
+			if section == nil {
+				return nil, fmt.Errorf("line %d: %s is not part of a profile", n, strings.TrimSpace(key))
+			}
+			value = strings.TrimSpace(value)
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			section[strings.TrimSpace(key)] = value
+		}
+	}
+
+	return profiles, scanner.Err()
+}
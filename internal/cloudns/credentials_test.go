@@ -0,0 +1,164 @@
+package cloudns
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testIniCredentials = `
+# shared accounts
+[default]
+auth_id  = 1234
+password = "default secret"
+
+[reseller]
+sub_auth_id = 99
+password    = reseller
+`
+
+const testJsonCredentials = `{
+  "default":  {"auth_id": 1234, "password": "default secret"},
+  "reseller": {"sub_auth_id": "99", "password": "reseller"}
+}`
+
+func writeCredentialsFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadCredentialsProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, path := range []string{
+		writeCredentialsFile(t, dir, "credentials", testIniCredentials),
+		writeCredentialsFile(t, dir, "credentials.json", testJsonCredentials),
+	} {
+		creds, err := readCredentialsProfile(path, "default")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds != (credentials{authId: 1234, password: "default secret"}) {
+			t.Errorf("%s: bad default profile: %+v", path, creds)
+		}
+
+		creds, err = readCredentialsProfile(path, "reseller")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds != (credentials{subAuthId: 99, password: "reseller"}) {
+			t.Errorf("%s: bad reseller profile: %+v", path, creds)
+		}
+
+		if _, err := readCredentialsProfile(path, "missing"); err == nil || !strings.Contains(err.Error(), `profile "missing" not found`) {
+			t.Errorf("%s: expected a missing profile to be reported, got %v", path, err)
+		}
+	}
+
+	for name, content := range map[string]string{
+		"no section":   "auth_id = 1",
+		"bad line":     "[default]\nauth_id",
+		"bad id":       "[default]\nauth_id = abc",
+		"unknown key":  "[default]\nauth-id = 1",
+		"bad json":     `{"default": {"auth_id": 1`,
+		"nested value": `{"default": {"auth_id": [1]}}`,
+	} {
+		path := writeCredentialsFile(t, dir, "invalid", content)
+		if _, err := readCredentialsProfile(path, "default"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestResolveCredentialsPrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVarAuthId, "")
+	t.Setenv(EnvVarSubAuthId, "")
+	t.Setenv(EnvVarPassword, "")
+	t.Setenv(EnvVarCredentialsFile, "")
+	t.Setenv(EnvVarProfile, "")
+
+	custom := writeCredentialsFile(t, t.TempDir(), "accounts.json", testJsonCredentials)
+
+	resolve := func(raw map[string]interface{}) credentials {
+		t.Helper()
+		creds, err := resolveCredentials(schema.TestResourceDataRaw(t, New()().Schema, raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return creds
+	}
+
+	if creds := resolve(nil); creds != (credentials{}) {
+		t.Errorf("expected no credentials without any source, got %+v", creds)
+	}
+
+	writeCredentialsFile(t, home, defaultCredentialsFile, testIniCredentials)
+	if creds := resolve(nil); creds != (credentials{authId: 1234, password: "default secret"}) {
+		t.Errorf("expected the default profile of the default file, got %+v", creds)
+	}
+
+	t.Setenv(EnvVarAuthId, "42")
+	t.Setenv(EnvVarPassword, "env secret")
+	if creds := resolve(nil); creds != (credentials{authId: 42, password: "env secret"}) {
+		t.Errorf("expected the environment to win over the default file, got %+v", creds)
+	}
+
+	if creds := resolve(map[string]interface{}{"profile": "reseller"}); creds != (credentials{subAuthId: 99, password: "reseller"}) {
+		t.Errorf("expected the profile to win over the environment, got %+v", creds)
+	}
+
+	t.Setenv(EnvVarProfile, "reseller")
+	t.Setenv(EnvVarCredentialsFile, custom)
+	if creds := resolve(nil); creds != (credentials{subAuthId: 99, password: "reseller"}) {
+		t.Errorf("expected the profile of the environment variables, got %+v", creds)
+	}
+
+	creds := resolve(map[string]interface{}{"credentials_file": custom, "profile": "default", "password": "explicit"})
+	if creds != (credentials{authId: 1234, password: "explicit"}) {
+		t.Errorf("expected the provider block to win over the profile, got %+v", creds)
+	}
+
+	creds = resolve(map[string]interface{}{"sub_auth_id": " 7\n", "password": "explicit"})
+	if creds != (credentials{subAuthId: 7, password: "explicit"}) {
+		t.Errorf("expected the provider block to win, got %+v", creds)
+	}
+
+	_, err := resolveCredentials(schema.TestResourceDataRaw(t, New()().Schema, map[string]interface{}{"profile": "missing"}))
+	if err == nil {
+		t.Error("expected a missing profile to be reported")
+	}
+}
+
+func TestValidateAuthId(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"1234":     true,
+		" 1234\n":  true,
+		"":         true,
+		"12ab":     false,
+		"-1":       false,
+		"0":        false,
+		"1234.5":   false,
+		"auth-id1": false,
+	} {
+		_, errs := validateAuthId(value, "auth_id")
+		if (len(errs) == 0) != valid {
+			t.Errorf("%q: expected valid to be %t, got %v", value, valid, errs)
+		}
+		for _, err := range errs {
+			if strings.Contains(err.Error(), value) {
+				t.Errorf("%q: the sensitive value must not be part of the error", value)
+			}
+		}
+	}
+}
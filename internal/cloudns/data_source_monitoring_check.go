@@ -0,0 +1,56 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceMonitoringCheck() *schema.Resource {
+	s := map[string]*schema.Schema{}
+	for k, v := range resourceMonitoringCheckSchema() {
+		s[k] = &schema.Schema{
+			Description: v.Description,
+			Type:        v.Type,
+			Computed:    true,
+		}
+	}
+
+	s["id"] = &schema.Schema{
+		Description: "The ID of the monitoring check.",
+		Type:        schema.TypeString,
+		Required:    true,
+	}
+
+	return &schema.Resource{
+		Description: "The settings and current status of a ClouDNS monitoring check.",
+
+		ReadContext: dataSourceMonitoringCheckRead,
+
+		Schema: s,
+	}
+}
+
+func dataSourceMonitoringCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	id := d.Get("id").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("READ Monitoring check #%s", id))
+
+	read, err := config.client.ReadMonitoringCheck(ctx, monitoringCheck{ID: id})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = updateMonitoringCheckState(d, &read)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(read.ID)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package cloudns
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// The kinds of failures resources branch on, match them with errors.Is
+var (
+	errNotFound     = errors.New("not found")
+	errAuth         = errors.New("authentication failed")
+	errUserDisabled = errors.New("API user disabled")
+	errRateLimited  = errors.New("rate limited")
+	errValidation   = errors.New("invalid request")
+	errIpNotAllowed = errors.New("IP address not allowed")
+	errZoneLimit    = errors.New("zone limit reached")
+	errUnavailable  = errors.New("temporarily unavailable")
+)
+
+// apiErrorKinds maps ClouDNS status descriptions to the kind of failure. ClouDNS only answers with a description,
+// so this is the one place where messages are matched. Patterns match whole descriptions, case insensitive and
+// with or without the trailing period, so e.g. "Invalid authentication, ..." is no validation error. Only descriptions
+// saying an object does not exist are taken for errNotFound, e.g. "Missing domain-name" is a validation error.
+// The first kind matching wins.
+var apiErrorKinds = []struct {
+	kind         error
+	descriptions []*regexp.Regexp
+}{
+	{errUserDisabled, apiDescriptions(
+		`this api user is disabled`,
+		`(this )?api user is not active`,
+		`api access is disabled`,
+	)},
+	{errAuth, apiDescriptions(
+		`invalid authentication, incorrect auth-id or auth-password`,
+		`missing (sub-)?auth-(id|password)`,
+	)},
+	{errIpNotAllowed, apiDescriptions(
+		`your ip( address)?( [0-9a-f.:]+)? is not allowed to (access|use) the api`,
+	)},
+	{errRateLimited, apiDescriptions(
+		`too many requests`,
+		`rate limit exceeded`,
+	)},
+	{errZoneLimit, apiDescriptions(
+		`you have reached the limit of zones in your plan`,
+		`maximum number of zones reached`,
+	)},
+	{errUnavailable, apiDescriptions(
+		`(the )?(service|api) is temporarily unavailable`,
+		`(service )?temporarily unavailable`,
+		`(please )?try again later`,
+	)},
+	{errNotFound, apiDescriptions(
+		`.+ not found`,
+		`.+ does not exist`,
+		`.+ doesn't exist`,
+		// returned by cloudns.Zone.Read when the zone is not in the account
+		`no zones returned in response`,
+	)},
+	{errValidation, apiDescriptions(
+		`invalid [a-z0-9_ -]+`,
+		`missing [a-z0-9_ -]+`,
+		`.+ must be .+`,
+		`.+ is not valid`,
+		`.+ is required`,
+	)},
+}
+
+// apiDescriptions compiles patterns matching whole status descriptions
+func apiDescriptions(patterns ...string) []*regexp.Regexp {
+	var descriptions []*regexp.Regexp
+	for _, p := range patterns {
+		descriptions = append(descriptions, regexp.MustCompile(`(?i)^(?:`+p+`)\.?$`))
+	}
+	return descriptions
+}
+
+// apiStatusError is the status description ClouDNS failed a request with, as opposed to the failures of
+// the provider itself like decoding the response
+type apiStatusError string
+
+func (e apiStatusError) Error() string {
+	return string(e)
+}
+
+// apiError is a failure reported by ClouDNS, its message includes the status description ClouDNS responded with
+type apiError struct {
+	kind error
+	desc string
+	err  error
+}
+
+func (e *apiError) Error() string {
+	return e.kind.Error() + ": " + e.desc
+}
+
+func (e *apiError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// toApiError maps the status description ClouDNS failed with to a typed error.
+// Errors which are typed already, or which can't be classified, are returned as is.
+func toApiError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// only descriptions ClouDNS responded with are classified, decoding failures and the like are ours
+	var typed *apiError
+	var status apiStatusError
+	if errors.As(err, &typed) || !errors.As(err, &status) {
+		return err
+	}
+
+	desc := err.Error()
+	for _, k := range apiErrorKinds {
+		for _, d := range k.descriptions {
+			if d.MatchString(strings.TrimSpace(string(status))) {
+				return &apiError{kind: k.kind, desc: desc, err: err}
+			}
+		}
+	}
+
+	return err
+}
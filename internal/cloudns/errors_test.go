@@ -0,0 +1,106 @@
+package cloudns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToApiError(t *testing.T) {
+	cases := map[string]error{
+		"Invalid authentication, incorrect auth-id or auth-password.": errAuth,
+		"Your IP address is not allowed to access the API.":           errIpNotAllowed,
+		"This API user is disabled.":                                  errUserDisabled,
+		"Too many requests.":                                          errRateLimited,
+		"You have reached the limit of zones in your plan.":           errZoneLimit,
+		"zone with domain example.com not found":                      errNotFound,
+		"no zones returned in response":                               errNotFound,
+		"Missing domain-name":                                         errValidation,
+		"Service temporarily unavailable.":                            errUnavailable,
+		"Invalid record type.":                                        errValidation,
+		"Invalid IP address.":                                         errValidation,
+	}
+
+	kinds := []error{errNotFound, errAuth, errUserDisabled, errRateLimited, errValidation, errIpNotAllowed, errZoneLimit, errUnavailable}
+
+	for desc, kind := range cases {
+		err := toApiError(apiStatusError(desc))
+
+		for _, k := range kinds {
+			if is := errors.Is(err, k); is != (k == kind) {
+				t.Errorf("%q: expected errors.Is(%v) to be %t", desc, k, !is)
+			}
+		}
+
+		if !strings.Contains(err.Error(), desc) {
+			t.Errorf("%q: expected the status description in %q", desc, err.Error())
+		}
+	}
+}
+
+// TestToApiErrorOverlappingDescriptions covers descriptions which share words with those of another kind
+func TestToApiErrorOverlappingDescriptions(t *testing.T) {
+	cases := []struct {
+		desc string
+		kind error
+	}{
+		{"Invalid authentication, incorrect auth-id or auth-password.", errAuth},
+		{"Missing auth-id", errAuth},
+		{"Missing auth-password", errAuth},
+		{"Missing domain-name", errValidation},
+		{"Missing record", errValidation},
+		{"Invalid record-id", errValidation},
+		{"Invalid notification-id", errValidation},
+		{"Invalid record-type", errValidation},
+		{"Invalid zone-type", errValidation},
+		{"Failover for this record does not exist.", errNotFound},
+		{"Dynamic URL for this record does not exist.", errNotFound},
+		{"Webhook not found.", errNotFound},
+		{"Your IP address 192.0.2.1 is not allowed to use the API.", errIpNotAllowed},
+		{"The record already exists.", nil},
+		{"The hostname of the record with this auth-id is invalid and missing a dot", nil},
+		{"Rate limit for invalid requests of this type is missing", nil},
+		{"Please try again later.", errUnavailable},
+		{"The zone was not updated, try again later to see if it is temporarily unavailable", nil},
+	}
+
+	for _, c := range cases {
+		err := toApiError(apiStatusError(c.desc))
+
+		var typed *apiError
+		if c.kind == nil {
+			if errors.As(err, &typed) {
+				t.Errorf("%q: expected no kind, got %v", c.desc, typed.kind)
+			}
+			continue
+		}
+		if !errors.As(err, &typed) || typed.kind != c.kind {
+			t.Errorf("%q: expected %v, got %v", c.desc, c.kind, err)
+		}
+	}
+}
+
+func TestToApiErrorPassesThrough(t *testing.T) {
+	for _, err := range []error{
+		errors.New("something unexpected"),
+		fmt.Errorf("error unmarshalling response: invalid character '<'"),
+		// only descriptions ClouDNS responded with are classified
+		errors.New("Record not found"),
+		apiStatusError("Something unexpected"),
+		apiHttpError{StatusCode: 502},
+	} {
+		if mapped := toApiError(err); mapped != err {
+			t.Errorf("expected %v to be returned as is, got %v", err, mapped)
+		}
+	}
+
+	if toApiError(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+
+	wrapped := fmt.Errorf("monitoring check 42: %w", errNotFound)
+	if !errors.Is(toApiError(wrapped), errNotFound) {
+		t.Error("expected wrapped sentinel errors to be kept")
+	}
+}
@@ -0,0 +1,683 @@
+package cloudns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	fakeApiAuthId   = "1234"
+	fakeApiPassword = "fake-password"
+)
+
+// fakeApiNameservers are the nameservers the fake API offers, new zones use the free ones unless told otherwise
+var fakeApiNameservers = []fakeNameserver{
+	{Type: "free", Name: "ns1.fake-cloudns.net"},
+	{Type: "free", Name: "ns2.fake-cloudns.net"},
+	{Type: "premium", Name: "pns1.fake-cloudns.net"},
+	{Type: "premium", Name: "pns2.fake-cloudns.net"},
+}
+
+type fakeNameserver struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// fakeApi is an in-memory ClouDNS API for the tests which must not reach the real one. It speaks the same
+// dialect: calls are authenticated, failures come back as a Failed status with the description ClouDNS uses.
+type fakeApi struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	nextId int
+	zones  map[string]*fakeZone
+}
+
+// fakeZone holds the records of a zone and everything attached to them, keyed by record ID
+type fakeZone struct {
+	ztype         string
+	records       map[int]fakeParams
+	failovers     map[int]fakeParams
+	notifications map[int][]failoverNotification
+	webhooks      map[int]map[string]failoverWebhook
+	dynamicUrls   map[int]string
+}
+
+// fakeParams is the JSON body of a request, or a stored record or failover
+type fakeParams map[string]interface{}
+
+func (p fakeParams) str(key string) string {
+	switch v := p[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (p fakeParams) int(key string) int {
+	i, _ := strconv.Atoi(p.str(key))
+	return i
+}
+
+// newFakeApi starts a fake API which is shut down at the end of the test
+func newFakeApi(t *testing.T) *fakeApi {
+	f := startFakeApi()
+	t.Cleanup(f.Close)
+	return f
+}
+
+func startFakeApi() *fakeApi {
+	f := &fakeApi{zones: map[string]*fakeZone{}}
+	f.Server = httptest.NewServer(f)
+	return f
+}
+
+// fakeApiHandlers implement the endpoints, they run with the state locked
+var fakeApiHandlers = map[string]func(f *fakeApi, p fakeParams) interface{}{
+	"/dns/login.json":                         func(f *fakeApi, p fakeParams) interface{} { return fakeSuccess("Success login.") },
+	"/dns/available-name-servers.json":        func(f *fakeApi, p fakeParams) interface{} { return fakeApiNameservers },
+	"/dns/register.json":                      (*fakeApi).registerZone,
+	"/dns/list-zones.json":                    (*fakeApi).listZones,
+	"/dns/delete.json":                        (*fakeApi).deleteZone,
+	"/dns/records.json":                       (*fakeApi).listRecords,
+	"/dns/add-record.json":                    (*fakeApi).addRecord,
+	"/dns/mod-record.json":                    (*fakeApi).modifyRecord,
+	"/dns/delete-record.json":                 (*fakeApi).deleteRecord,
+	"/dns/failover-activate.json":             (*fakeApi).activateFailover,
+	"/dns/failover-modify.json":               (*fakeApi).modifyFailover,
+	"/dns/failover-settings.json":             (*fakeApi).failoverSettings,
+	"/dns/failover-deactivate.json":           (*fakeApi).deactivateFailover,
+	"/dns/failover-notifications-list.json":   (*fakeApi).listNotifications,
+	"/dns/failover-notifications-add.json":    (*fakeApi).addNotification,
+	"/dns/failover-notifications-delete.json": (*fakeApi).deleteNotification,
+	"/dns/failover-webhook-get.json":          (*fakeApi).getWebhook,
+	"/dns/failover-webhook-set.json":          (*fakeApi).setWebhook,
+	"/dns/failover-webhook-delete.json":       (*fakeApi).deleteWebhook,
+	"/dns/get-dynamic-url.json":               (*fakeApi).getDynamicUrl,
+	"/dns/list-dynamic-url.json":              (*fakeApi).listDynamicUrl,
+	"/dns/change-dynamic-url.json":            (*fakeApi).changeDynamicUrl,
+	"/dns/disable-dynamic-url.json":           (*fakeApi).disableDynamicUrl,
+}
+
+func (f *fakeApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, ok := fakeApiHandlers[r.URL.Path]
+	if !ok || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	params := fakeParams{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		params = fakeParams{}
+	}
+
+	var resp interface{}
+	if id := params.str("auth-id") + params.str("sub-auth-id"); id != fakeApiAuthId || params.str("auth-password") != fakeApiPassword {
+		resp = fakeFailed("Invalid authentication, incorrect auth-id or auth-password.")
+	} else {
+		f.mu.Lock()
+		resp = handler(f, params)
+		f.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func fakeSuccess(desc string) interface{} {
+	return apiStatus{Status: "Success", Desc: desc}
+}
+
+func fakeFailed(desc string) interface{} {
+	return apiStatus{Status: "Failed", Desc: desc}
+}
+
+// AddZone creates a zone the way registering it does
+func (f *fakeApi) AddZone(domain string, ztype string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.registerZone(fakeParams{"domain-name": domain, "zone-type": ztype})
+}
+
+// Records returns the records of a zone as ClouDNS lists them
+func (f *fakeApi) Records(domain string) []fakeParams {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone, ok := f.zones[domain]
+	if !ok {
+		return nil
+	}
+
+	var records []fakeParams
+	for _, id := range sortedIds(zone.records) {
+		records = append(records, zone.records[id])
+	}
+	return records
+}
+
+// ClientConfig configures the provider against the fake API the way Terraform does
+func (f *fakeApi) ClientConfig(t *testing.T) ClientConfig {
+	return fakeApiClientConfig(t, f.URL)
+}
+
+// fakeApiClientConfig configures the provider with the credentials of the fake API against endpoint
+func fakeApiClientConfig(t *testing.T, endpoint string) ClientConfig {
+	d := schema.TestResourceDataRaw(t, New()().Schema, map[string]interface{}{
+		"auth_id":      fakeApiAuthId,
+		"password":     fakeApiPassword,
+		"api_endpoint": endpoint,
+		"rate_limit":   1000,
+	})
+
+	meta, diags := configure()(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("cannot configure the provider against the fake API: %+v", diags)
+	}
+	return meta.(ClientConfig)
+}
+
+func (f *fakeApi) id() int {
+	f.nextId++
+	return f.nextId
+}
+
+func (f *fakeApi) zone(p fakeParams) (*fakeZone, interface{}) {
+	domain := p.str("domain-name")
+	if domain == "" {
+		return nil, fakeFailed("Missing domain-name")
+	}
+	zone, ok := f.zones[domain]
+	if !ok {
+		return nil, fakeFailed("Zone " + domain + " does not exist.")
+	}
+	return zone, nil
+}
+
+func (f *fakeApi) record(p fakeParams) (*fakeZone, int, interface{}) {
+	zone, failed := f.zone(p)
+	if failed != nil {
+		return nil, 0, failed
+	}
+
+	id := p.int("record-id")
+	if id == 0 {
+		return nil, 0, fakeFailed("Missing record-id")
+	}
+	if _, ok := zone.records[id]; !ok {
+		return nil, 0, fakeFailed("Record does not exist.")
+	}
+	return zone, id, nil
+}
+
+func (f *fakeApi) failover(p fakeParams) (*fakeZone, int, interface{}) {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return nil, 0, failed
+	}
+
+	if _, ok := zone.failovers[id]; !ok {
+		return nil, 0, fakeFailed("Failover for this record does not exist.")
+	}
+	return zone, id, nil
+}
+
+func sortedIds[T any](m map[int]T) []int {
+	var ids []int
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// ZONES
+
+func (f *fakeApi) registerZone(p fakeParams) interface{} {
+	domain := p.str("domain-name")
+	if domain == "" {
+		return fakeFailed("Missing domain-name")
+	}
+	if _, ok := f.zones[domain]; ok {
+		return fakeFailed("Zone " + domain + " is already registered.")
+	}
+
+	ztype := p.str("zone-type")
+	if !slices.Contains([]string{"master", "slave", "parked", "geodns"}, ztype) {
+		return fakeFailed("Invalid zone-type")
+	}
+	if ztype == "slave" && p.str("master-ip") == "" {
+		return fakeFailed("Missing master-ip")
+	}
+
+	zone := &fakeZone{
+		ztype:         ztype,
+		records:       map[int]fakeParams{},
+		failovers:     map[int]fakeParams{},
+		notifications: map[int][]failoverNotification{},
+		webhooks:      map[int]map[string]failoverWebhook{},
+		dynamicUrls:   map[int]string{},
+	}
+	f.zones[domain] = zone
+
+	var nameservers []string
+	if ns, ok := p["ns"].([]interface{}); ok && len(ns) > 0 {
+		for _, n := range ns {
+			nameservers = append(nameservers, fmt.Sprint(n))
+		}
+	} else {
+		for _, ns := range fakeApiNameservers {
+			if ns.Type == "free" {
+				nameservers = append(nameservers, ns.Name)
+			}
+		}
+	}
+
+	if ztype == "master" || ztype == "geodns" {
+		for _, ns := range nameservers {
+			id := f.id()
+			zone.records[id] = fakeParams{"id": strconv.Itoa(id), "type": "NS", "host": "", "record": ns, "ttl": "3600", "status": 1}
+		}
+	}
+
+	return fakeSuccess("Domain zone " + domain + " was created successfully.")
+}
+
+func (f *fakeApi) listZones(p fakeParams) interface{} {
+	var domains []string
+	for domain := range f.zones {
+		if strings.Contains(domain, p.str("search")) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+
+	listed := []fakeParams{}
+	for _, domain := range fakePage(domains, p) {
+		listed = append(listed, fakeParams{"name": domain, "type": f.zones[domain].ztype, "zone": "domain", "status": "1"})
+	}
+	return listed
+}
+
+func (f *fakeApi) deleteZone(p fakeParams) interface{} {
+	if _, failed := f.zone(p); failed != nil {
+		return failed
+	}
+
+	delete(f.zones, p.str("domain-name"))
+	return fakeSuccess("Zone " + p.str("domain-name") + " was deleted successfully.")
+}
+
+// fakePage returns the page of items asked for with page and rows-per-page
+func fakePage[T any](items []T, p fakeParams) []T {
+	page, rows := p.int("page"), p.int("rows-per-page")
+	if page < 1 || rows < 1 {
+		return items
+	}
+
+	start := min((page-1)*rows, len(items))
+	end := min(start+rows, len(items))
+	return items[start:end]
+}
+
+// RECORDS
+
+// fakeRecordTypes are the record types the fake API accepts
+var fakeRecordTypes = []string{"A", "AAAA", "MX", "CNAME", "TXT", "SPF", "NS", "SRV", "WR", "ALIAS", "RP", "SSHFP", "PTR", "NAPTR", "CAA", "TLSA", "DS", "CERT", "HINFO", "LOC", "SMIMEA", "OPENPGPKEY", "DNAME"}
+
+func (f *fakeApi) listRecords(p fakeParams) interface{} {
+	zone, failed := f.zone(p)
+	if failed != nil {
+		return failed
+	}
+
+	var matching []fakeParams
+	for _, id := range sortedIds(zone.records) {
+		record := zone.records[id]
+		if p.str("host") != "" && record.str("host") != p.str("host") {
+			continue
+		}
+		if p.str("type") != "" && record.str("type") != p.str("type") {
+			continue
+		}
+		matching = append(matching, record)
+	}
+
+	page := fakePage(matching, p)
+	if len(page) == 0 {
+		// ClouDNS lists no records as an empty array, but records as an object keyed by ID
+		return []fakeParams{}
+	}
+
+	keyed := map[string]fakeParams{}
+	for _, record := range page {
+		keyed[record.str("id")] = record
+	}
+	return keyed
+}
+
+func (f *fakeApi) addRecord(p fakeParams) interface{} {
+	zone, failed := f.zone(p)
+	if failed != nil {
+		return failed
+	}
+
+	rtype := p.str("record-type")
+	if !slices.Contains(fakeRecordTypes, rtype) {
+		return fakeFailed("Invalid record-type")
+	}
+	if p.str("record") == "" {
+		return fakeFailed("Missing record")
+	}
+	if p.str("ttl") == "" {
+		return fakeFailed("Missing ttl")
+	}
+
+	for _, existing := range zone.records {
+		if existing.str("type") == rtype && existing.str("host") == p.str("host") && existing.str("record") == p.str("record") {
+			return fakeFailed("The record already exists.")
+		}
+	}
+
+	id := f.id()
+	record := fakeRecord(p)
+	record["id"] = strconv.Itoa(id)
+	record["type"] = rtype
+	record["status"] = 1
+	zone.records[id] = record
+
+	return map[string]interface{}{
+		"status":            "Success",
+		"statusDescription": "The record was added successfully.",
+		"data":              map[string]interface{}{"id": id},
+	}
+}
+
+func (f *fakeApi) modifyRecord(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+	if p.str("record") == "" {
+		return fakeFailed("Missing record")
+	}
+
+	for key, value := range fakeRecord(p) {
+		zone.records[id][key] = value
+	}
+	return fakeSuccess("The record was modified successfully.")
+}
+
+func (f *fakeApi) deleteRecord(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+
+	delete(zone.records, id)
+	delete(zone.failovers, id)
+	delete(zone.notifications, id)
+	delete(zone.webhooks, id)
+	delete(zone.dynamicUrls, id)
+	return fakeSuccess("The record was deleted successfully.")
+}
+
+// fakeRecord keeps the fields of a record from a request, named the way records are listed
+func fakeRecord(p fakeParams) fakeParams {
+	record := fakeParams{}
+	for key, value := range p {
+		switch key {
+		case "auth-id", "sub-auth-id", "auth-password", "domain-name", "record-id", "record-type":
+		default:
+			record[key] = value
+		}
+	}
+	return record
+}
+
+// FAILOVERS
+
+// fakeCheckSettings are the failover fields ClouDNS returns nested in check_settings
+var fakeCheckSettings = []string{"host", "port", "path", "content", "query_type", "query_response", "latency_limit", "timeout", "http_request_type", "packet_count"}
+
+func (f *fakeApi) activateFailover(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+
+	if rtype := zone.records[id].str("type"); rtype != "A" && rtype != "AAAA" {
+		return fakeFailed("Failover is available only for A and AAAA records.")
+	}
+	if _, ok := zone.failovers[id]; ok {
+		return fakeFailed("Failover is already activated for this record.")
+	}
+
+	return f.storeFailover(zone, id, p, "Failover was activated successfully.")
+}
+
+func (f *fakeApi) modifyFailover(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	return f.storeFailover(zone, id, p, "Failover was modified successfully.")
+}
+
+func (f *fakeApi) storeFailover(zone *fakeZone, id int, p fakeParams, desc string) interface{} {
+	if p.str("check_type") == "" {
+		return fakeFailed("Missing check_type")
+	}
+	if p.str("main_ip") == "" {
+		return fakeFailed("Missing main_ip")
+	}
+
+	settings := fakeRecord(p)
+	delete(settings, "id")
+	zone.failovers[id] = settings
+	return fakeSuccess(desc)
+}
+
+func (f *fakeApi) failoverSettings(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	settings := fakeParams{}
+	checkSettings := fakeParams{}
+	for key, value := range zone.failovers[id] {
+		if slices.Contains(fakeCheckSettings, key) {
+			checkSettings[key] = value
+		} else {
+			settings[key] = value
+		}
+	}
+	settings["check_settings"] = checkSettings
+	return settings
+}
+
+func (f *fakeApi) deactivateFailover(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	delete(zone.failovers, id)
+	delete(zone.notifications, id)
+	delete(zone.webhooks, id)
+	return fakeSuccess("Failover was deactivated successfully.")
+}
+
+func (f *fakeApi) listNotifications(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	notifications := fakePage(zone.notifications[id], p)
+	if notifications == nil {
+		return []failoverNotification{}
+	}
+	return notifications
+}
+
+func (f *fakeApi) addNotification(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+	if p.str("type") != "mail" && p.str("type") != "sms" {
+		return fakeFailed("Invalid type")
+	}
+	if p.str("value") == "" {
+		return fakeFailed("Missing value")
+	}
+
+	zone.notifications[id] = append(zone.notifications[id], failoverNotification{
+		ID:    strconv.Itoa(f.id()),
+		Type:  p.str("type"),
+		Value: p.str("value"),
+	})
+	return fakeSuccess("The notification was added successfully.")
+}
+
+func (f *fakeApi) deleteNotification(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	notifications := zone.notifications[id]
+	for i, n := range notifications {
+		if n.ID == p.str("notification-id") {
+			zone.notifications[id] = slices.Delete(notifications, i, i+1)
+			return fakeSuccess("The notification was deleted successfully.")
+		}
+	}
+	return fakeFailed("Notification does not exist.")
+}
+
+func (f *fakeApi) getWebhook(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+
+	webhook, ok := zone.webhooks[id][p.str("event")]
+	if !ok {
+		return fakeFailed("Webhook not found.")
+	}
+	return webhook
+}
+
+func (f *fakeApi) setWebhook(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+	if p.str("event") != "up" && p.str("event") != "down" {
+		return fakeFailed("Invalid event")
+	}
+	if !strings.HasPrefix(p.str("url"), "http://") && !strings.HasPrefix(p.str("url"), "https://") {
+		return fakeFailed("Invalid url")
+	}
+
+	if zone.webhooks[id] == nil {
+		zone.webhooks[id] = map[string]failoverWebhook{}
+	}
+	zone.webhooks[id][p.str("event")] = failoverWebhook{Url: p.str("url"), Method: p.str("method"), Payload: p.str("payload")}
+	return fakeSuccess("The webhook was set successfully.")
+}
+
+func (f *fakeApi) deleteWebhook(p fakeParams) interface{} {
+	zone, id, failed := f.failover(p)
+	if failed != nil {
+		return failed
+	}
+	if _, ok := zone.webhooks[id][p.str("event")]; !ok {
+		return fakeFailed("Webhook not found.")
+	}
+
+	delete(zone.webhooks[id], p.str("event"))
+	return fakeSuccess("The webhook was deleted successfully.")
+}
+
+// DYNAMIC URLS
+
+func fakeDynamicUrl() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return "https://ipv4.cloudns.net/api/dynamicURL/?q=" + hex.EncodeToString(token)
+}
+
+func (f *fakeApi) getDynamicUrl(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+	if rtype := zone.records[id].str("type"); rtype != "A" && rtype != "AAAA" {
+		return fakeFailed("Dynamic URL is available only for A and AAAA records.")
+	}
+
+	if _, ok := zone.dynamicUrls[id]; !ok {
+		zone.dynamicUrls[id] = fakeDynamicUrl()
+	}
+	return map[string]string{"host": zone.records[id].str("host"), "url": zone.dynamicUrls[id]}
+}
+
+func (f *fakeApi) listDynamicUrl(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+
+	// ClouDNS answers with an empty url when the record has no dynamic URL
+	return map[string]string{"host": zone.records[id].str("host"), "url": zone.dynamicUrls[id]}
+}
+
+func (f *fakeApi) changeDynamicUrl(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+	if _, ok := zone.dynamicUrls[id]; !ok {
+		return fakeFailed("Dynamic URL for this record does not exist.")
+	}
+
+	zone.dynamicUrls[id] = fakeDynamicUrl()
+	return map[string]string{"host": zone.records[id].str("host"), "url": zone.dynamicUrls[id]}
+}
+
+func (f *fakeApi) disableDynamicUrl(p fakeParams) interface{} {
+	zone, id, failed := f.record(p)
+	if failed != nil {
+		return failed
+	}
+	if _, ok := zone.dynamicUrls[id]; !ok {
+		return fakeFailed("Dynamic URL for this record does not exist.")
+	}
+
+	delete(zone.dynamicUrls, id)
+	return fakeSuccess("Dynamic URL was disabled successfully.")
+}
@@ -0,0 +1,83 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// apiSecretPatterns match the parts of requests and responses which must never be logged: the credentials
+// sent along with every call, and the token of dynamic URLs which allows anyone to change a record
+var apiSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`"(?:sub-)?auth-(?:id|password)"\s*:\s*(?:"(?:[^"\\]|\\.)*"|[0-9]+)`),
+	regexp.MustCompile(`[?&]q=[^"&\s\\]+`),
+}
+
+// loggingTransport logs every API request and response at debug level, with the secrets masked by tflog
+type loggingTransport struct {
+	next     http.RoundTripper
+	password string
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := redactedLogContext(req.Context(), t.password)
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	tflog.Debug(ctx, "ClouDNS API request", map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+		"body":   string(reqBody),
+	})
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		tflog.Debug(ctx, "ClouDNS API request failed", map[string]interface{}{
+			"path":     req.URL.Path,
+			"duration": time.Since(start).String(),
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+
+	tflog.Debug(ctx, "ClouDNS API response", map[string]interface{}{
+		"path":     req.URL.Path,
+		"status":   resp.StatusCode,
+		"duration": time.Since(start).String(),
+		"body":     string(respBody),
+	})
+
+	return resp, nil
+}
+
+// redactedLogContext masks the credentials and dynamic URL tokens in everything logged through ctx
+func redactedLogContext(ctx context.Context, password string) context.Context {
+	ctx = tflog.MaskAllFieldValuesRegexes(ctx, apiSecretPatterns...)
+	ctx = tflog.MaskMessageRegexes(ctx, apiSecretPatterns...)
+	if password != "" {
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, password)
+		ctx = tflog.MaskMessageStrings(ctx, password)
+	}
+	return ctx
+}
@@ -0,0 +1,73 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingTransportMasksSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"host": "www", "url": "https:\/\/ipv4.cloudns.net\/api\/dynamicURL\/?q=c2VjcmV0dG9rZW4"}`))
+	}))
+	defer server.Close()
+
+	access := cloudns.Apiaccess{Authid: 4242, Authpassword: "s3cr3t-p4ss"}
+	conn, err := newApiConn(access, server.URL, httpSettings{timeout: time.Second, debugLogging: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	var resp cloudns.DynamicUrlResponse
+	err = conn.request(ctx, "/dns/get-dynamic-url.json", map[string]interface{}{"domain-name": "example.com"}, &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(resp.Url, "?q=c2VjcmV0dG9rZW4") {
+		t.Fatalf("expected the response to be passed on untouched, got %+v", resp)
+	}
+
+	logged := output.String()
+	entries, err := tflogtest.MultilineJSONDecode(strings.NewReader(logged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the request and the response to be logged, got %+v", entries)
+	}
+
+	for _, entry := range entries {
+		logged += entry["body"].(string)
+	}
+	for _, secret := range []string{"4242", "s3cr3t-p4ss", "c2VjcmV0dG9rZW4"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %s to be masked, got %s", secret, logged)
+		}
+	}
+	if !strings.Contains(entries[0]["body"].(string), "example.com") || entries[0]["path"] != "/dns/get-dynamic-url.json" {
+		t.Errorf("expected the request parameters to be logged, got %+v", entries[0])
+	}
+	if !strings.Contains(entries[1]["body"].(string), "dynamicURL") || entries[1]["status"] != 200.0 {
+		t.Errorf("expected the response to be logged, got %+v", entries[1])
+	}
+}
+
+func TestLoggingTransportIsOptIn(t *testing.T) {
+	conn, err := newApiConn(cloudns.Apiaccess{}, defaultApiEndpoint, httpSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := conn.http.Transport.(*loggingTransport); ok {
+		t.Fatal("expected requests not to be logged by default")
+	}
+}
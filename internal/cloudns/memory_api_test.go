@@ -0,0 +1,412 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+// memoryApi keeps zones, records, failovers, monitoring checks and dynamic URLs in memory, so resources can be
+// tested without HTTP. It reports missing objects with errNotFound, like apiClient does.
+type memoryApi struct {
+	mu     sync.Mutex
+	nextId int
+
+	zones         map[string]cloudns.Zone
+	records       map[string]map[string]cloudns.Record
+	failovers     map[string]apiFailover
+	notifications map[string][]failoverNotification
+	webhooks      map[string]failoverWebhook
+	checks        map[string]monitoringCheck
+	dynamicUrls   map[string]string
+
+	// renumberOnUpdate gives records a new ID when they are updated
+	renumberOnUpdate bool
+}
+
+func newMemoryApi() *memoryApi {
+	return &memoryApi{
+		zones:         map[string]cloudns.Zone{},
+		records:       map[string]map[string]cloudns.Record{},
+		failovers:     map[string]apiFailover{},
+		notifications: map[string][]failoverNotification{},
+		webhooks:      map[string]failoverWebhook{},
+		checks:        map[string]monitoringCheck{},
+		dynamicUrls:   map[string]string{},
+	}
+}
+
+var _ cloudnsApi = (*memoryApi)(nil)
+
+func (m *memoryApi) id() string {
+	m.nextId++
+	return strconv.Itoa(m.nextId)
+}
+
+// ZONES
+
+func (m *memoryApi) ListNameservers(ctx context.Context) ([]cloudns.Ns, error) {
+	return []cloudns.Ns{
+		{Type: "free", Name: "ns1.example.net"},
+		{Type: "premium", Name: "pns1.example.net"},
+	}, nil
+}
+
+func (m *memoryApi) ListZones(ctx context.Context, search string) ([]cloudns.Zone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var zones []cloudns.Zone
+	for domain, zone := range m.zones {
+		if strings.Contains(domain, search) {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Domain < zones[j].Domain })
+	return zones, nil
+}
+
+func (m *memoryApi) CreateZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.zones[zone.Domain]; ok {
+		return zone, fmt.Errorf("zone %s already exists", zone.Domain)
+	}
+
+	m.zones[zone.Domain] = zone
+	m.records[zone.Domain] = map[string]cloudns.Record{}
+	for _, ns := range zone.Ns {
+		id := m.id()
+		m.records[zone.Domain][id] = cloudns.Record{Domain: zone.Domain, ID: id, Rtype: "NS", Record: ns, TTL: 3600}
+	}
+
+	return zone, nil
+}
+
+func (m *memoryApi) ReadZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	read, ok := m.zones[zone.Domain]
+	if !ok {
+		return zone, fmt.Errorf("zone %s: %w", zone.Domain, errNotFound)
+	}
+	return read, nil
+}
+
+func (m *memoryApi) DeleteZone(ctx context.Context, zone cloudns.Zone) (cloudns.Zone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.zones[zone.Domain]; !ok {
+		return zone, fmt.Errorf("zone %s: %w", zone.Domain, errNotFound)
+	}
+
+	delete(m.zones, zone.Domain)
+	delete(m.records, zone.Domain)
+	return zone, nil
+}
+
+// RECORDS
+
+func (m *memoryApi) ListRecords(ctx context.Context, domain string) ([]cloudns.Record, error) {
+	return m.FindRecords(ctx, domain, recordFilter{})
+}
+
+func (m *memoryApi) FindRecords(ctx context.Context, domain string, filter recordFilter) ([]cloudns.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zone, ok := m.records[domain]
+	if !ok {
+		return nil, fmt.Errorf("zone %s: %w", domain, errNotFound)
+	}
+
+	var records []cloudns.Record
+	for _, record := range zone {
+		if filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return recordId(records[i]) < recordId(records[j])
+	})
+
+	return records, nil
+}
+
+func (m *memoryApi) CreateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zone, ok := m.records[record.Domain]
+	if !ok {
+		return record, fmt.Errorf("zone %s: %w", record.Domain, errNotFound)
+	}
+
+	record.ID = m.id()
+	zone[record.ID] = record
+	return record, nil
+}
+
+func (m *memoryApi) UpdateRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zone := m.records[record.Domain]
+	existing, ok := zone[record.ID]
+	if !ok {
+		return record, fmt.Errorf("record %s: %w", record.ID, errNotFound)
+	}
+
+	// the type of a record can't be modified
+	record.Rtype = existing.Rtype
+	if m.renumberOnUpdate {
+		delete(zone, record.ID)
+		record.ID = m.id()
+	}
+	zone[record.ID] = record
+
+	return record, nil
+}
+
+func (m *memoryApi) DeleteRecord(ctx context.Context, record cloudns.Record) (cloudns.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zone := m.records[record.Domain]
+	if _, ok := zone[record.ID]; !ok {
+		return record, fmt.Errorf("record %s: %w", record.ID, errNotFound)
+	}
+
+	delete(zone, record.ID)
+	return record, nil
+}
+
+// FAILOVERS
+
+func failoverKey(domain string, recordId string) string {
+	return domain + "/" + recordId
+}
+
+func (m *memoryApi) CreateFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[failover.Domain][failover.RecordId]; !ok {
+		return failover, fmt.Errorf("record %s: %w", failover.RecordId, errNotFound)
+	}
+
+	m.failovers[failoverKey(failover.Domain, failover.RecordId)] = failover
+	return failover, nil
+}
+
+func (m *memoryApi) ReadFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	read, ok := m.failovers[failoverKey(failover.Domain, failover.RecordId)]
+	if !ok {
+		return failover, fmt.Errorf("failover %s: %w", failover.RecordId, errNotFound)
+	}
+	return read, nil
+}
+
+func (m *memoryApi) UpdateFailover(ctx context.Context, failover apiFailover) (apiFailover, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(failover.Domain, failover.RecordId)
+	if _, ok := m.failovers[key]; !ok {
+		return failover, fmt.Errorf("failover %s: %w", failover.RecordId, errNotFound)
+	}
+
+	m.failovers[key] = failover
+	return failover, nil
+}
+
+func (m *memoryApi) DeleteFailover(ctx context.Context, failover apiFailover) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(failover.Domain, failover.RecordId)
+	if _, ok := m.failovers[key]; !ok {
+		return fmt.Errorf("failover %s: %w", failover.RecordId, errNotFound)
+	}
+
+	delete(m.failovers, key)
+	delete(m.notifications, key)
+	return nil
+}
+
+func (m *memoryApi) ListFailoverNotifications(ctx context.Context, domain string, recordId string) ([]failoverNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(domain, recordId)
+	if _, ok := m.failovers[key]; !ok {
+		return nil, fmt.Errorf("failover %s: %w", recordId, errNotFound)
+	}
+	return append([]failoverNotification(nil), m.notifications[key]...), nil
+}
+
+func (m *memoryApi) AddFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(domain, recordId)
+	if _, ok := m.failovers[key]; !ok {
+		return fmt.Errorf("failover %s: %w", recordId, errNotFound)
+	}
+
+	notification.ID = m.id()
+	m.notifications[key] = append(m.notifications[key], notification)
+	return nil
+}
+
+func (m *memoryApi) DeleteFailoverNotification(ctx context.Context, domain string, recordId string, notification failoverNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(domain, recordId)
+	for i, n := range m.notifications[key] {
+		if n.ID == notification.ID {
+			m.notifications[key] = append(m.notifications[key][:i], m.notifications[key][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("notification %s: %w", notification.ID, errNotFound)
+}
+
+func (m *memoryApi) ReadFailoverWebhook(ctx context.Context, domain string, recordId string, event string) (failoverWebhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, ok := m.webhooks[failoverKey(domain, recordId)+"/"+event]
+	if !ok {
+		return webhook, fmt.Errorf("%s webhook: %w", event, errNotFound)
+	}
+	return webhook, nil
+}
+
+func (m *memoryApi) SetFailoverWebhook(ctx context.Context, domain string, recordId string, event string, webhook failoverWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.webhooks[failoverKey(domain, recordId)+"/"+event] = webhook
+	return nil
+}
+
+func (m *memoryApi) DeleteFailoverWebhook(ctx context.Context, domain string, recordId string, event string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.webhooks, failoverKey(domain, recordId)+"/"+event)
+	return nil
+}
+
+// MONITORING
+
+func (m *memoryApi) CreateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	check.ID = m.id()
+	m.checks[check.ID] = check
+	return check, nil
+}
+
+func (m *memoryApi) ReadMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	read, ok := m.checks[check.ID]
+	if !ok {
+		return check, fmt.Errorf("monitoring check %s: %w", check.ID, errNotFound)
+	}
+	return read, nil
+}
+
+func (m *memoryApi) UpdateMonitoringCheck(ctx context.Context, check monitoringCheck) (monitoringCheck, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.checks[check.ID]; !ok {
+		return check, fmt.Errorf("monitoring check %s: %w", check.ID, errNotFound)
+	}
+
+	m.checks[check.ID] = check
+	return check, nil
+}
+
+func (m *memoryApi) DeleteMonitoringCheck(ctx context.Context, check monitoringCheck) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.checks[check.ID]; !ok {
+		return fmt.Errorf("monitoring check %s: %w", check.ID, errNotFound)
+	}
+
+	delete(m.checks, check.ID)
+	return nil
+}
+
+// DYNAMIC URLS
+
+func (m *memoryApi) ReadOrCreateDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[dynUrl.Domain][dynUrl.RecordId]; !ok {
+		return cloudns.DynamicUrlResponse{}, fmt.Errorf("record %s: %w", dynUrl.RecordId, errNotFound)
+	}
+
+	key := failoverKey(dynUrl.Domain, dynUrl.RecordId)
+	if _, ok := m.dynamicUrls[key]; !ok {
+		m.dynamicUrls[key] = "https://ipv4.cloudns.net/api/dynamicURL/?q=" + m.id()
+	}
+	return cloudns.DynamicUrlResponse{Domain: dynUrl.Domain, RecordId: dynUrl.RecordId, Url: m.dynamicUrls[key]}, nil
+}
+
+func (m *memoryApi) ReadDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	url, ok := m.dynamicUrls[failoverKey(dynUrl.Domain, dynUrl.RecordId)]
+	if !ok {
+		return cloudns.DynamicUrlResponse{}, fmt.Errorf("dynamic URL for record %s: %w", dynUrl.RecordId, errNotFound)
+	}
+	return cloudns.DynamicUrlResponse{Domain: dynUrl.Domain, RecordId: dynUrl.RecordId, Url: url}, nil
+}
+
+func (m *memoryApi) ChangeDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) (cloudns.DynamicUrlResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(dynUrl.Domain, dynUrl.RecordId)
+	if _, ok := m.dynamicUrls[key]; !ok {
+		return cloudns.DynamicUrlResponse{}, fmt.Errorf("dynamic URL for record %s: %w", dynUrl.RecordId, errNotFound)
+	}
+
+	m.dynamicUrls[key] = "https://ipv4.cloudns.net/api/dynamicURL/?q=" + m.id()
+	return cloudns.DynamicUrlResponse{Domain: dynUrl.Domain, RecordId: dynUrl.RecordId, Url: m.dynamicUrls[key]}, nil
+}
+
+func (m *memoryApi) DeleteDynamicUrl(ctx context.Context, dynUrl cloudns.DynamicUrl) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := failoverKey(dynUrl.Domain, dynUrl.RecordId)
+	if _, ok := m.dynamicUrls[key]; !ok {
+		return fmt.Errorf("dynamic URL for record %s: %w", dynUrl.RecordId, errNotFound)
+	}
+
+	delete(m.dynamicUrls, key)
+	return nil
+}
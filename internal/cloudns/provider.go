@@ -2,18 +2,26 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"go.uber.org/ratelimit"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const EnvVarAuthId = "CLOUDNS_AUTH_ID"
 const EnvVarSubAuthId = "CLOUDNS_SUB_AUTH_ID"
 const EnvVarPassword = "CLOUDNS_PASSWORD"
+const EnvVarApiEndpoint = "CLOUDNS_API_ENDPOINT"
+const EnvVarHttpTimeout = "CLOUDNS_HTTP_TIMEOUT"
+const EnvVarProxyUrl = "CLOUDNS_PROXY_URL"
+const EnvVarCaBundle = "CLOUDNS_CA_BUNDLE"
+const EnvVarCredentialsFile = "CLOUDNS_CREDENTIALS_FILE"
+const EnvVarProfile = "CLOUDNS_PROFILE"
+const EnvVarHttpDebugLogging = "CLOUDNS_HTTP_DEBUG_LOGGING"
 
 func init() {
 	schema.DescriptionKind = schema.StringMarkdown
@@ -24,43 +32,125 @@ func New() func() *schema.Provider {
 
 		providerSchema := map[string]*schema.Schema{
 			"auth_id": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc(EnvVarAuthId, nil),
-				Description: fmt.Sprintf("When using api users, this is the `auth-id`. It is read from the environment variable `%s` if not passed explicitly. Mutually exclusive with `sub_auth_id`.", EnvVarAuthId),
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validation.ToDiagFunc(validateAuthId),
+				Description:      fmt.Sprintf("When using api users, this is the numeric `auth-id`, given as a number or a string. It is read from the environment variable `%s` or the credentials file if not passed explicitly. Mutually exclusive with `sub_auth_id`.", EnvVarAuthId),
 			},
 			"sub_auth_id": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc(EnvVarSubAuthId, nil),
-				Description: fmt.Sprintf("When using api sub-users, this is the `sub-auth-id`. It is read from the environment variable `%s` if not passed explicitly. Mutually exclusive with `auth_id`.", EnvVarSubAuthId),
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validation.ToDiagFunc(validateAuthId),
+				Description:      fmt.Sprintf("When using api sub-users, this is the numeric `sub-auth-id`, given as a number or a string. It is read from the environment variable `%s` or the credentials file if not passed explicitly. Mutually exclusive with `auth_id`.", EnvVarSubAuthId),
 			},
+			// the SDK does not allow WriteOnly in provider schemas, which is fine as provider configuration is never
+			// persisted to state and Terraform accepts ephemeral values for it, these are not written to the plan
 			"password": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc(EnvVarPassword, nil),
-				Description: fmt.Sprintf("This is the password associated with your auth-id or sub-auth-id. It is read from the environment variable `%s` if not passed explicitly.", EnvVarPassword),
+				Description: fmt.Sprintf("This is the password associated with your auth-id or sub-auth-id. It is read from the environment variable `%s` or the credentials file if not passed explicitly. Accepts ephemeral values, e.g. from an ephemeral resource or an ephemeral variable, so it is not stored in the plan.", EnvVarPassword),
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(EnvVarCredentialsFile, nil),
+				Description: fmt.Sprintf("Path to an INI or JSON file with named credential profiles. It is read from the environment variable `%s` if not passed explicitly. Defaults to `~/.cloudns/credentials`.", EnvVarCredentialsFile),
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(EnvVarProfile, nil),
+				Description: fmt.Sprintf("The profile of the credentials file to use. When set, the `%s`, `%s` and `%s` environment variables are ignored. It is read from the environment variable `%s` if not passed explicitly. Defaults to `%s`.", EnvVarAuthId, EnvVarSubAuthId, EnvVarPassword, EnvVarProfile, defaultProfile),
 			},
 			"rate_limit": {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Sensitive:   false,
 				Default:     5,
-				Description: "Underlying rate limit (in API calls per second) to observe while interacting with ClouDNS. Defaults to 5 requests per second.",
+				Description: "Underlying rate limit (in API calls per second) to observe while interacting with ClouDNS. Every provider configuration observes a limit of its own, aliased configurations of the same account have to split it between them. Defaults to 5 requests per second.",
+			},
+			"adaptive_rate_limit": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Lower the rate at which ClouDNS is called when it throttles, and ramp it back up to `rate_limit` after a run of successful calls. Defaults to `false`.",
+			},
+			"api_endpoint": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc(EnvVarApiEndpoint, defaultApiEndpoint),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsURLWithHTTPorHTTPS),
+				Description:      fmt.Sprintf("The base URL of the ClouDNS API, e.g. to use a local stand-in for testing. It is read from the environment variable `%s` if not passed explicitly. Defaults to `%s`.", EnvVarApiEndpoint, defaultApiEndpoint),
+			},
+			"http_timeout": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc(EnvVarHttpTimeout, defaultHttpTimeout),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      fmt.Sprintf("Timeout (in seconds) of a single HTTP request to the ClouDNS API. It is read from the environment variable `%s` if not passed explicitly. Defaults to %d seconds.", EnvVarHttpTimeout, defaultHttpTimeout),
+			},
+			"proxy_url": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc(EnvVarProxyUrl, nil),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsURLWithScheme([]string{"http", "https", "socks5"})),
+				Description:      fmt.Sprintf("The proxy to send requests to the ClouDNS API through. It is read from the environment variable `%s` if not passed explicitly. If neither is set, the standard `HTTPS_PROXY` and `NO_PROXY` environment variables are honored.", EnvVarProxyUrl),
+			},
+			"ca_bundle": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(EnvVarCaBundle, nil),
+				Description: fmt.Sprintf("Path to a PEM file with certificate authorities to trust in addition to the system ones, e.g. for a TLS intercepting proxy. It is read from the environment variable `%s` if not passed explicitly.", EnvVarCaBundle),
+			},
+			"http_debug_logging": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc(EnvVarHttpDebugLogging, false),
+				Description: fmt.Sprintf("Log every request to the ClouDNS API and its response at debug level (`TF_LOG=DEBUG`), with the credentials and dynamic URLs masked. It is read from the environment variable `%s` if not passed explicitly. Defaults to `false`.", EnvVarHttpDebugLogging),
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip checking the credentials with ClouDNS when the provider is configured. Defaults to `false`.",
+			},
+			"serialize_zone_writes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Make changes to the records and failovers of a zone one at a time, while changes to different zones still run in parallel. ClouDNS may end up with duplicate records when the same zone is changed concurrently. Defaults to `true`.",
+			},
+			"max_retries": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          defaultMaxRetries,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      fmt.Sprintf("How many times an API call failing because of throttling, a server side or a network error is retried, with exponential backoff. Validation and authentication errors are never retried. Set to 0 to disable retries. Defaults to %d.", defaultMaxRetries),
+			},
+			"retry_max_wait": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          defaultRetryMaxWait,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      fmt.Sprintf("The maximum time (in seconds) a single API call may take including all of its retries. Defaults to %d seconds.", defaultRetryMaxWait),
 			},
 		}
 
 		p := &schema.Provider{
-			Schema:         providerSchema,
-			DataSourcesMap: map[string]*schema.Resource{},
+			Schema: providerSchema,
+			DataSourcesMap: map[string]*schema.Resource{
+				"cloudns_monitoring_check": dataSourceMonitoringCheck(),
+			},
 			ResourcesMap: map[string]*schema.Resource{
-				"cloudns_dns_record":   resourceDnsRecord(),
-				"cloudns_dns_zone":     resourceDnsZone(),
-				"cloudns_dns_failover": resourceDnsFailover(),
-				"cloudns_dynamic_url":  resourceDynamicUrl(),
+				"cloudns_dns_record":            resourceDnsRecord(),
+				"cloudns_dns_zone":              resourceDnsZone(),
+				"cloudns_dns_failover":          resourceDnsFailover(),
+				"cloudns_dynamic_url":           resourceDynamicUrl(),
+				"cloudns_failover_notification": resourceFailoverNotification(),
+				"cloudns_monitoring_check":      resourceMonitoringCheck(),
 			},
 		}
 
@@ -71,15 +161,16 @@ func New() func() *schema.Provider {
 }
 
 type ClientConfig struct {
-	apiAccess   cloudns.Apiaccess
-	rateLimiter ratelimit.Limiter
+	client cloudnsApi
 }
 
 func configure() func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		authId := d.Get("auth_id").(int)
-		subAuthId := d.Get("sub_auth_id").(int)
-		password := d.Get("password").(string)
+		creds, err := resolveCredentials(d)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		authId, subAuthId, password := creds.authId, creds.subAuthId, creds.password
 
 		if len(password) == 0 {
 			return nil, diag.Errorf("Expected password to be defined but it wasn't")
@@ -93,30 +184,70 @@ func configure() func(context.Context, *schema.ResourceData) (interface{}, diag.
 			return nil, diag.Errorf("Exactly one of auth_id or sub_auth_id must be set, but both were %s", golangSucks)
 		}
 
-		rateLimiter := ratelimit.New(
-			d.Get("rate_limit").(int),
-			// while slack is a thing, we can't reliably assume it's impactful
-			ratelimit.WithoutSlack,
-			ratelimit.Per(time.Second),
+		retry := newRetryPolicy(
+			d.Get("max_retries").(int),
+			time.Duration(d.Get("retry_max_wait").(int))*time.Second,
 		)
 
-		if authId != 0 {
-			return ClientConfig{
-				apiAccess: cloudns.Apiaccess{
-					Authid:       authId,
-					Authpassword: password,
-				},
-				rateLimiter: rateLimiter,
-			}, nil
-		} else {
-			return ClientConfig{
-				apiAccess: cloudns.Apiaccess{
-					Subauthid:    subAuthId,
-					Authpassword: password,
-				},
-				rateLimiter: rateLimiter,
-			}, nil
+		access := cloudns.Apiaccess{
+			Authid:       authId,
+			Subauthid:    subAuthId,
+			Authpassword: password,
 		}
 
+		conn, err := newApiConn(access, d.Get("api_endpoint").(string), httpSettings{
+			timeout:      time.Duration(d.Get("http_timeout").(int)) * time.Second,
+			proxyUrl:     d.Get("proxy_url").(string),
+			caBundle:     d.Get("ca_bundle").(string),
+			debugLogging: d.Get("http_debug_logging").(bool),
+		})
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		// Terraform runs every provider configuration in a plugin process of its own, so there is no sharing the
+		// limiter with aliased configurations of the same account
+		rateLimiter := newRateLimiter(d.Get("rate_limit").(int), d.Get("adaptive_rate_limit").(bool))
+
+		client := newApiClient(conn, rateLimiter, retry)
+		if d.Get("serialize_zone_writes").(bool) {
+			client.zoneWrites = newZoneLocks()
+		}
+
+		if !d.Get("skip_credentials_validation").(bool) {
+			if err := client.Login(ctx); err != nil {
+				return nil, credentialsDiag(err)
+			}
+		}
+
+		return ClientConfig{
+			client: client,
+		}, nil
+	}
+}
+
+// credentialsDiag explains why ClouDNS did not accept the credentials
+func credentialsDiag(err error) diag.Diagnostics {
+	summary := "Unable to validate the ClouDNS credentials"
+	detail := "Set `skip_credentials_validation = true` to configure the provider without checking the credentials."
+
+	switch {
+	case errors.Is(err, errAuth):
+		summary = "Invalid ClouDNS credentials"
+		detail = "ClouDNS rejected the `auth_id` / `sub_auth_id` and `password`, check that they belong to an API user or sub-user."
+	case errors.Is(err, errIpNotAllowed):
+		summary = "IP address not allowed by ClouDNS"
+		detail = "The credentials are restricted to a list of IP addresses, add the address Terraform runs from in the ClouDNS API settings."
+	case errors.Is(err, errUserDisabled):
+		summary = "ClouDNS API user is disabled"
+		detail = "Enable the API user or sub-user in the ClouDNS API settings."
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  summary,
+			Detail:   fmt.Sprintf("%s\n\nClouDNS responded: %v", detail, err),
+		},
 	}
 }
@@ -2,11 +2,18 @@ package cloudns
 
 import (
 	"context"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 const EnvVarAcceptanceTestsZone = "CLOUDNS_ACCEPTANCE_TESTS_ZONE"
@@ -25,7 +32,42 @@ func TestProviderDeclaration(t *testing.T) {
 
 var testAccProvider = New()()
 
+// fakeAcceptanceZone is the zone the acceptance tests manage records in when they run against the fake API
+const fakeAcceptanceZone = "acceptance-tests.example.com"
+
+// testAccFakeApi is shared by all acceptance tests, like the zone of a real account is
+var testAccFakeApi = sync.OnceValue(func() *fakeApi {
+	api := startFakeApi()
+	api.AddZone(fakeAcceptanceZone, "master")
+	return api
+})
+
+// testAccUseRealApi tells whether the acceptance tests run against ClouDNS, they run against the fake API unless TF_ACC is set
+func testAccUseRealApi() bool {
+	return os.Getenv(resource.EnvTfAcc) != ""
+}
+
+func acceptanceTestsZone() string {
+	if !testAccUseRealApi() {
+		return fakeAcceptanceZone
+	}
+	return os.Getenv(EnvVarAcceptanceTestsZone)
+}
+
 func testAccPreCheck(t *testing.T) {
+	if testAccUseRealApi() {
+		testAccPreCheckRealApi(t)
+	} else {
+		testAccPreCheckFakeApi(t)
+	}
+
+	err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testAccPreCheckRealApi(t *testing.T) {
 	authId := os.Getenv(EnvVarAuthId)
 	subAuthId := os.Getenv(EnvVarSubAuthId)
 	if authId == "" && subAuthId == "" {
@@ -43,9 +85,90 @@ func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv(EnvVarAcceptanceTestsZone); v == "" {
 		t.Fatalf("%s must be set for acceptance tests but it wasn't set.", EnvVarAcceptanceTestsZone)
 	}
+}
 
-	err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil))
-	if err != nil {
-		t.Fatal(err)
+// testAccPreCheckFakeApi points the provider at the fake API. The Terraform CLI still runs the tests, they are
+// skipped rather than downloading it when it isn't installed, unless they run in CI.
+func testAccPreCheckFakeApi(t *testing.T) {
+	_, err := exec.LookPath("terraform")
+	if err != nil && os.Getenv("TF_ACC_TERRAFORM_PATH") == "" && os.Getenv("TF_ACC_TERRAFORM_VERSION") == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("Terraform CLI not found, install it or set TF_ACC_TERRAFORM_PATH to run the acceptance tests in CI")
+		}
+		t.Skip("Terraform CLI not found, set TF_ACC_TERRAFORM_PATH to run the acceptance tests against the fake API")
+	}
+
+	t.Setenv(EnvVarApiEndpoint, testAccFakeApi().URL)
+	t.Setenv(EnvVarAuthId, fakeApiAuthId)
+	t.Setenv(EnvVarSubAuthId, "")
+	t.Setenv(EnvVarPassword, fakeApiPassword)
+	t.Setenv(EnvVarCredentialsFile, "")
+	t.Setenv(EnvVarProfile, "")
+}
+
+func TestConfigureValidatesCredentials(t *testing.T) {
+	var description string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/login.json" {
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+		if description == "" {
+			w.Write([]byte(`{"status": "Success", "statusDescription": "Success login."}`))
+			return
+		}
+		fmt.Fprintf(w, `{"status": "Failed", "statusDescription": %q}`, description)
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		description string
+		skip        bool
+		summary     string
+	}{
+		"valid":           {},
+		"bad credentials": {description: "Invalid authentication, incorrect auth-id or auth-password.", summary: "Invalid ClouDNS credentials"},
+		"ip restricted":   {description: "Your IP address is not allowed to access the API.", summary: "IP address not allowed by ClouDNS"},
+		"disabled":        {description: "This API user is disabled.", summary: "ClouDNS API user is disabled"},
+		"skipped":         {description: "Invalid authentication, incorrect auth-id or auth-password.", skip: true},
+	}
+
+	for name, c := range cases {
+		description = c.description
+
+		d := schema.TestResourceDataRaw(t, New()().Schema, map[string]interface{}{
+			"auth_id":                     "42",
+			"password":                    "secret",
+			"api_endpoint":                server.URL,
+			"max_retries":                 0,
+			"skip_credentials_validation": c.skip,
+		})
+
+		_, diags := configure()(context.Background(), d)
+		if c.summary == "" {
+			if diags.HasError() {
+				t.Errorf("%s: unexpected error: %+v", name, diags)
+			}
+			continue
+		}
+
+		if len(diags) != 1 || diags[0].Summary != c.summary || !strings.Contains(diags[0].Detail, c.description) {
+			t.Errorf("%s: bad diagnostics: %+v", name, diags)
+		}
+	}
+}
+
+func TestResourcesDeclareTimeouts(t *testing.T) {
+	// zones can't be updated, a timeout for updates would do nothing
+	notUpdatable := map[string]bool{"cloudns_dns_zone": true}
+
+	for name, resource := range New()().ResourcesMap {
+		timeouts := resource.Timeouts
+		if timeouts == nil || timeouts.Create == nil || timeouts.Read == nil || timeouts.Delete == nil {
+			t.Errorf("%s: expected timeouts for every operation, got %+v", name, timeouts)
+			continue
+		}
+		if (timeouts.Update == nil) != notUpdatable[name] {
+			t.Errorf("%s: expected a timeout for updates only if it can be updated, got %+v", name, timeouts)
+		}
 	}
 }
@@ -0,0 +1,113 @@
+package cloudns
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// adaptiveMinRate is the lowest rate (in API calls per second) throttling lowers the adaptive limit to
+	adaptiveMinRate = 0.5
+	// adaptiveDecrease is applied to the rate when ClouDNS throttles
+	adaptiveDecrease = 0.5
+	// adaptiveIncrease is applied to the rate after adaptiveRecoveryRun successful calls in a row
+	adaptiveIncrease    = 1.25
+	adaptiveRecoveryRun = 20
+	// adaptiveCooldown keeps calls throttled at the same time from lowering the rate more than once
+	adaptiveCooldown = time.Second
+)
+
+// rateLimiter paces the calls made to ClouDNS
+type rateLimiter interface {
+	// Wait blocks until the next call is allowed, or fails once the context is done
+	Wait(ctx context.Context) error
+}
+
+// rateFeedback is implemented by limiters adapting to how ClouDNS responds
+type rateFeedback interface {
+	throttled(ctx context.Context)
+	succeeded(ctx context.Context)
+}
+
+func newRateLimiter(perSecond int, adaptive bool) rateLimiter {
+	if adaptive {
+		return newAdaptiveLimiter(float64(perSecond))
+	}
+
+	// while a burst is a thing, we can't reliably assume it's impactful
+	return rate.NewLimiter(rate.Limit(perSecond), 1)
+}
+
+// adaptiveLimiter spaces calls like rateLimiter, but at a rate which is lowered multiplicatively when
+// ClouDNS throttles and ramped up again after a run of successes, bounded by maxRate
+type adaptiveLimiter struct {
+	limiter *rate.Limiter
+
+	mu          sync.Mutex
+	maxRate     float64
+	rate        float64
+	successes   int
+	lastLowered time.Time
+}
+
+func newAdaptiveLimiter(maxRate float64) *adaptiveLimiter {
+	return &adaptiveLimiter{limiter: rate.NewLimiter(rate.Limit(maxRate), 1), maxRate: maxRate, rate: maxRate}
+}
+
+func (l *adaptiveLimiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+func (l *adaptiveLimiter) throttled(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.successes = 0
+	if time.Since(l.lastLowered) < adaptiveCooldown {
+		return
+	}
+	l.lastLowered = time.Now()
+
+	lowered := max(l.rate*adaptiveDecrease, min(adaptiveMinRate, l.maxRate))
+	if lowered == l.rate {
+		return
+	}
+	l.setRate(lowered)
+	tflog.Warn(ctx, fmt.Sprintf("ClouDNS is throttling, lowering the rate limit to %.2f requests per second", lowered))
+}
+
+func (l *adaptiveLimiter) succeeded(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate >= l.maxRate {
+		return
+	}
+
+	l.successes++
+	if l.successes < adaptiveRecoveryRun {
+		return
+	}
+	l.successes = 0
+
+	l.setRate(min(l.rate*adaptiveIncrease, l.maxRate))
+	tflog.Info(ctx, fmt.Sprintf("Raising the rate limit to %.2f requests per second", l.rate))
+}
+
+// setRate changes the rate in place, so calls already waiting are paced by it as well
+func (l *adaptiveLimiter) setRate(perSecond float64) {
+	l.rate = perSecond
+	l.limiter.SetLimit(rate.Limit(perSecond))
+}
+
+func (l *adaptiveLimiter) currentRate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
@@ -0,0 +1,84 @@
+package cloudns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := newAdaptiveLimiter(8)
+
+	limiter.throttled(ctx)
+	if rate := limiter.currentRate(); rate != 4 {
+		t.Fatalf("expected the rate to be halved, got %.2f", rate)
+	}
+
+	// calls throttled at the same time lower the rate once
+	limiter.throttled(ctx)
+	if rate := limiter.currentRate(); rate != 4 {
+		t.Fatalf("expected the rate to be lowered once, got %.2f", rate)
+	}
+
+	for i := 0; i < 10; i++ {
+		limiter.lastLowered = time.Time{}
+		limiter.throttled(ctx)
+	}
+	if rate := limiter.currentRate(); rate != adaptiveMinRate {
+		t.Fatalf("expected the rate to be bounded by %.2f, got %.2f", adaptiveMinRate, rate)
+	}
+
+	for i := 0; i < adaptiveRecoveryRun-1; i++ {
+		limiter.succeeded(ctx)
+	}
+	if rate := limiter.currentRate(); rate != adaptiveMinRate {
+		t.Fatalf("expected the rate to be raised after a run of successes only, got %.2f", rate)
+	}
+	limiter.succeeded(ctx)
+	if rate := limiter.currentRate(); rate != adaptiveMinRate*adaptiveIncrease {
+		t.Fatalf("expected the rate to be raised, got %.2f", rate)
+	}
+
+	for i := 0; i < 100*adaptiveRecoveryRun; i++ {
+		limiter.succeeded(ctx)
+	}
+	if rate := limiter.currentRate(); rate != 8 {
+		t.Fatalf("expected the rate to be bounded by the rate limit, got %.2f", rate)
+	}
+}
+
+func TestAdaptiveLimiterSpacesCalls(t *testing.T) {
+	limiter := newAdaptiveLimiter(100)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected calls to be spaced by 10ms, took %s", elapsed)
+	}
+}
+
+func TestApiClientReportsThrottling(t *testing.T) {
+	limiter := newAdaptiveLimiter(1000)
+	client := newApiClient(&apiConn{}, limiter, retryPolicy{})
+
+	client.call(context.Background(), "test", func(ctx context.Context, conn *apiConn) error {
+		return apiHttpError{StatusCode: 429}
+	})
+	if rate := limiter.currentRate(); rate != 500 {
+		t.Fatalf("expected throttling to lower the rate, got %.2f", rate)
+	}
+
+	for i := 0; i < adaptiveRecoveryRun; i++ {
+		client.call(context.Background(), "test", func(ctx context.Context, conn *apiConn) error {
+			return nil
+		})
+	}
+	if rate := limiter.currentRate(); rate != 500*adaptiveIncrease {
+		t.Fatalf("expected successes to raise the rate, got %.2f", rate)
+	}
+}
@@ -2,8 +2,10 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -20,6 +22,14 @@ func resourceDnsRecord() *schema.Resource {
 		ReadContext:   resourceDnsRecordRead,
 		UpdateContext: resourceDnsRecordUpdate,
 		DeleteContext: resourceDnsRecordDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		CustomizeDiff: resourceDnsRecordValidate,
 
 		Importer: &schema.ResourceImporter{
@@ -374,8 +384,7 @@ func resourceDnsRecordCreate(ctx context.Context, d *schema.ResourceData, meta i
 
 	tflog.Debug(ctx, fmt.Sprintf("CREATE %s.%s %d in %s %s", recordToCreate.Host, recordToCreate.Domain, recordToCreate.TTL, recordToCreate.Rtype, recordToCreate.Record))
 
-	clientConfig.rateLimiter.Take()
-	recordCreated, err := recordToCreate.Create(&clientConfig.apiAccess)
+	recordCreated, err := clientConfig.client.CreateRecord(ctx, recordToCreate)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -396,26 +405,30 @@ func resourceDnsRecordRead(ctx context.Context, d *schema.ResourceData, meta int
 
 	tflog.Debug(ctx, fmt.Sprintf("READ Record#%s (%s.%s %d in %s %s)", lookup.ID, lookup.Host, lookup.Domain, lookup.TTL, lookup.Rtype, lookup.Record))
 
-	config.rateLimiter.Take()
-	zoneRead, err := cloudns.Zone{Domain: lookup.Domain}.List(&config.apiAccess)
-	if err != nil {
-		if isNotFoundError(err) {
-			d.SetId("")
-			return nil
-		}
+	// look the record up by host and type first, which keeps reads cheap in large zones. The whole zone is listed
+	// before the record is taken for gone, as its host or type may have been changed outside of Terraform.
+	filters := []recordFilter{{host: lookup.Host, rtype: lookup.Rtype}, {}}
+	for _, filter := range filters {
+		zoneRead, err := config.client.FindRecords(ctx, lookup.Domain, filter)
+		if err != nil {
+			if errors.Is(err, errNotFound) {
+				d.SetId("")
+				return nil
+			}
 
-		return diag.FromErr(err)
-	}
+			return diag.FromErr(err)
+		}
 
-	for _, zoneRecord := range zoneRead {
-		wantedId := d.Id()
-		actualId := zoneRecord.ID
-		if wantedId == actualId {
-			err = updateState(d, &zoneRecord)
-			if err != nil {
-				return diag.FromErr(err)
+		for _, zoneRecord := range zoneRead {
+			wantedId := d.Id()
+			actualId := zoneRecord.ID
+			if wantedId == actualId {
+				err = updateState(d, &zoneRecord)
+				if err != nil {
+					return diag.FromErr(err)
+				}
+				return nil
 			}
-			return nil
 		}
 	}
 
@@ -429,8 +442,7 @@ func resourceDnsRecordUpdate(ctx context.Context, d *schema.ResourceData, meta i
 
 	tflog.Debug(ctx, fmt.Sprintf("UPDATE %s.%s %d in %s %s", record.Host, record.Domain, record.TTL, record.Rtype, record.Record))
 
-	config.rateLimiter.Take()
-	updated, err := record.Update(&config.apiAccess)
+	updated, err := config.client.UpdateRecord(ctx, record)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -446,13 +458,13 @@ func resourceDnsRecordDelete(ctx context.Context, d *schema.ResourceData, meta i
 
 	tflog.Debug(ctx, fmt.Sprintf("DELETE %s.%s %d in %s %s", record.Host, record.Domain, record.TTL, record.Rtype, record.Record))
 
-	config.rateLimiter.Take()
-	_, err := record.Destroy(&config.apiAccess)
+	_, err := config.client.DeleteRecord(ctx, record)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	return resourceDnsRecordRead(ctx, d, meta)
+	d.SetId("")
+	return nil
 }
 
 func resourceDnsRecordValidate(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
@@ -474,8 +486,7 @@ func resourceDnsRecordImport(ctx context.Context, d *schema.ResourceData, meta i
 	zone := parts[0]
 	wantedId := parts[1]
 
-	config.rateLimiter.Take()
-	zoneRead, err := cloudns.Zone{Domain: zone}.List(&config.apiAccess)
+	zoneRead, err := config.client.ListRecords(ctx, zone)
 	if err != nil {
 		return nil, err
 	}
@@ -529,8 +540,78 @@ func updateState(d *schema.ResourceData, zoneRecord *cloudns.Record) error {
 		return err
 	}
 
-	if zoneRecord.Rtype == "MX" {
-		err = d.Set("priority", zoneRecord.Priority)
+	// the same attributes toApiRecord reads for the type of the record
+	attributes := map[string]interface{}{}
+	switch zoneRecord.Rtype {
+	case "MX":
+		attributes["priority"] = zoneRecord.Priority
+	case "WR":
+		attributes["frame"] = zoneRecord.Frame
+		attributes["frametitle"] = zoneRecord.FrameTitle
+		attributes["framekeywords"] = zoneRecord.FrameKeywords
+		attributes["framedescription"] = zoneRecord.FrameDescription
+		attributes["mobilemeta"] = zoneRecord.MobileMeta
+		attributes["savepath"] = zoneRecord.SavePath
+		attributes["redirecttype"] = zoneRecord.RedirectType
+	case "SRV":
+		attributes["priority"] = zoneRecord.Priority
+		attributes["weight"] = zoneRecord.Weight
+		attributes["port"] = zoneRecord.Port
+	case "RP":
+		attributes["mail"] = zoneRecord.Mail
+		attributes["txt"] = zoneRecord.Txt
+	case "SSHFP":
+		attributes["algorithm"] = zoneRecord.Algorithm
+		attributes["fptype"] = zoneRecord.Fptype
+	case "NAPTR":
+		attributes["flag"] = zoneRecord.Flag
+		attributes["order"] = zoneRecord.Order
+		attributes["pref"] = zoneRecord.Pref
+		attributes["params"] = zoneRecord.Params
+		attributes["regexp"] = zoneRecord.Regexp
+		attributes["replace"] = zoneRecord.Replace
+	case "CAA":
+		attributes["caaflag"] = zoneRecord.CaaFlag
+		attributes["caatype"] = zoneRecord.CaaType
+		attributes["caavalue"] = zoneRecord.CaaValue
+	case "TLSA":
+		attributes["tlsausage"] = zoneRecord.TlsaUsage
+		attributes["tlsaselector"] = zoneRecord.TlsaSelector
+		attributes["tlsamatchingtype"] = zoneRecord.TlsaMatchingType
+	case "DS":
+		attributes["keytag"] = zoneRecord.KeyTag
+		attributes["algorithm"] = zoneRecord.Algorithm
+		attributes["digesttype"] = zoneRecord.DigestType
+	case "CERT":
+		attributes["certtype"] = zoneRecord.CertType
+		attributes["certkeytag"] = zoneRecord.CertKeyTag
+		attributes["certalgorithm"] = zoneRecord.CertAlgorithm
+	case "HINFO":
+		attributes["cpu"] = zoneRecord.CPU
+		attributes["os"] = zoneRecord.OS
+	case "LOC":
+		attributes["latdeg"] = zoneRecord.LatDeg
+		attributes["latmin"] = zoneRecord.LatMin
+		attributes["latsec"] = zoneRecord.LatSec
+		attributes["latdir"] = zoneRecord.LatDir
+		attributes["longdeg"] = zoneRecord.LongDeg
+		attributes["longmin"] = zoneRecord.LongMin
+		attributes["longsec"] = zoneRecord.LongSec
+		attributes["longdir"] = zoneRecord.LongDir
+		attributes["altitude"] = zoneRecord.Altitude
+		attributes["size"] = zoneRecord.Size
+		attributes["hprecision"] = zoneRecord.HPrecision
+		attributes["vprecision"] = zoneRecord.VPrecision
+	case "SMIMEA":
+		attributes["smimeausage"] = zoneRecord.SmimeaUsage
+		attributes["smimeaselector"] = zoneRecord.SmimeaSelector
+		attributes["smimeamatchingtype"] = zoneRecord.SmimeaMatchingType
+	}
+	attributes["geodnslocation"] = zoneRecord.GeodnsLocation
+	attributes["geodnscode"] = zoneRecord.GeodnsCode
+
+	for key, value := range attributes {
+		err = d.Set(key, value)
 		if err != nil {
 			return err
 		}
@@ -582,6 +663,7 @@ func toApiRecord(d *schema.ResourceData) cloudns.Record {
 	certalgorithm := 0
 	latdeg := 0.0
 	latmin := 0.0
+	latsec := 0.0
 	latdir := ""
 	longdeg := 0.0
 	longmin := 0.0
@@ -634,7 +716,7 @@ func toApiRecord(d *schema.ResourceData) cloudns.Record {
 		algorithm = d.Get("algorithm").(int)
 		digesttype = d.Get("digesttype").(int)
 	} else if rtype == "CERT" {
-		certtype = d.Get("keytag").(int)
+		certtype = d.Get("certtype").(int)
 		certkeytag = d.Get("certkeytag").(int)
 		certalgorithm = d.Get("certalgorithm").(int)
 	} else if rtype == "HINFO" {
@@ -643,6 +725,7 @@ func toApiRecord(d *schema.ResourceData) cloudns.Record {
 	} else if rtype == "LOC" {
 		latdeg = d.Get("latdeg").(float64)
 		latmin = d.Get("latmin").(float64)
+		latsec = d.Get("latsec").(float64)
 		latdir = d.Get("latdir").(string)
 		longdeg = d.Get("longdeg").(float64)
 		longmin = d.Get("longmin").(float64)
@@ -709,6 +792,7 @@ func toApiRecord(d *schema.ResourceData) cloudns.Record {
 		OS:                 os,
 		LatDeg:             latdeg,
 		LatMin:             latmin,
+		LatSec:             latsec,
 		LatDir:             latdir,
 		LongDeg:            longdeg,
 		LongMin:            longmin,
@@ -725,11 +809,3 @@ func toApiRecord(d *schema.ResourceData) cloudns.Record {
 		GeodnsCode:         geodnscode,
 	}
 }
-
-func isNotFoundError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errorMsg := err.Error()
-	return strings.Contains(errorMsg, "not found") || strings.Contains(errorMsg, "Missing domain-name")
-}
@@ -1,18 +1,26 @@
 package cloudns
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
 	"testing"
+	"testing/quick"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
-var testZone = os.Getenv(EnvVarAcceptanceTestsZone)
+var testZone = acceptanceTestsZone()
 
 const recordTpl = `
 resource "cloudns_dns_record" "%s" {
@@ -189,11 +197,8 @@ func TestAccDnsImportMXRecord(t *testing.T) {
 
 func CheckDestroyedRecords(state *terraform.State) error {
 	provider := testAccProvider
-	apiAccess := provider.Meta().(ClientConfig).apiAccess
-	records, err := cloudns.Zone{
-		Ztype:  "master",
-		Domain: testZone,
-	}.List(&apiAccess)
+	client := provider.Meta().(ClientConfig).client
+	records, err := client.ListRecords(context.Background(), testZone)
 
 	if err != nil {
 		return err
@@ -224,3 +229,377 @@ func CheckDestroyedRecords(state *terraform.State) error {
 
 	return nil
 }
+
+func TestDnsRecordLifecycleAgainstFakeApi(t *testing.T) {
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	config := api.ClientConfig(t)
+	ctx := context.Background()
+
+	d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{
+		"name":     "www",
+		"zone":     "example.com",
+		"type":     "MX",
+		"value":    "mail.example.com",
+		"ttl":      600,
+		"priority": 10,
+	})
+
+	if diags := resourceDnsRecordCreate(ctx, d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+	if d.Id() == "" || d.Get("priority") != 10 {
+		t.Fatalf("expected the created record in state, got %s %+v", d.Id(), d.State())
+	}
+
+	d.Set("value", "mail2.example.com")
+	d.Set("priority", 0)
+	if diags := resourceDnsRecordUpdate(ctx, d, config); diags.HasError() {
+		t.Fatalf("update: %+v", diags)
+	}
+
+	records := api.Records("example.com")
+	stored := records[len(records)-1]
+	if stored.str("id") != d.Id() || stored.str("record") != "mail2.example.com" || stored.str("priority") != "0" {
+		t.Fatalf("expected the record to be modified, got %+v", stored)
+	}
+
+	if diags := resourceDnsRecordDelete(ctx, d, config); diags.HasError() {
+		t.Fatalf("delete: %+v", diags)
+	}
+
+	d.SetId(stored.str("id"))
+	if diags := resourceDnsRecordRead(ctx, d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected the deleted record to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestDnsRecordReadNotFound(t *testing.T) {
+	api := newMemoryApi()
+	api.CreateZone(context.Background(), cloudns.Zone{Domain: "example.com", Ztype: "master"})
+	config := ClientConfig{client: api}
+
+	d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{
+		"name":  "www",
+		"zone":  "example.com",
+		"type":  "A",
+		"value": "1.2.3.4",
+		"ttl":   600,
+	})
+	if diags := resourceDnsRecordCreate(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+
+	api.DeleteRecord(context.Background(), toApiRecord(d))
+	if diags := resourceDnsRecordRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a record deleted outside of Terraform to be removed from state, got %s %+v", d.Id(), diags)
+	}
+
+	d.SetId("1")
+	api.DeleteZone(context.Background(), cloudns.Zone{Domain: "example.com"})
+	if diags := resourceDnsRecordRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a record of a deleted zone to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestDnsRecordReadChangedOutsideTerraform(t *testing.T) {
+	cases := map[string]struct {
+		change func(r *cloudns.Record)
+		// sibling keeps a record at the old host and type, without it the filtered lookup finds nothing
+		sibling bool
+	}{
+		"renamed":              {change: func(r *cloudns.Record) { r.Host = "web" }},
+		"renamed with sibling": {change: func(r *cloudns.Record) { r.Host = "web" }, sibling: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			api := newMemoryApi()
+			api.CreateZone(ctx, cloudns.Zone{Domain: "example.com", Ztype: "master"})
+			config := ClientConfig{client: api}
+
+			d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{
+				"name":  "www",
+				"zone":  "example.com",
+				"type":  "A",
+				"value": "1.2.3.4",
+				"ttl":   600,
+			})
+			if diags := resourceDnsRecordCreate(ctx, d, config); diags.HasError() {
+				t.Fatalf("create: %+v", diags)
+			}
+			if c.sibling {
+				api.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "5.6.7.8", TTL: 600})
+			}
+
+			changed := toApiRecord(d)
+			c.change(&changed)
+			api.UpdateRecord(ctx, changed)
+
+			if diags := resourceDnsRecordRead(ctx, d, config); diags.HasError() {
+				t.Fatalf("read: %+v", diags)
+			}
+			if d.Id() != changed.ID || d.Get("name") != changed.Host || d.Get("type") != changed.Rtype {
+				t.Fatalf("expected the record changed outside of Terraform to be found, got %s %+v", d.Id(), d.State())
+			}
+		})
+	}
+}
+
+func TestDnsRecordReadListsZoneBeforeRemoving(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+
+	var listings atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns/records.json" {
+			listings.Add(1)
+		}
+		api.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cases := map[string]struct {
+		host     string
+		listings int32
+	}{
+		"deleted record":      {host: "www", listings: 2},
+		"deleted apex record": {host: "", listings: 2},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			config := fakeApiClientConfig(t, server.URL)
+			d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{
+				"name":  c.host,
+				"zone":  "example.com",
+				"type":  "A",
+				"value": "1.2.3.4",
+				"ttl":   600,
+			})
+			if diags := resourceDnsRecordCreate(ctx, d, config); diags.HasError() {
+				t.Fatalf("create: %+v", diags)
+			}
+			if _, err := config.client.DeleteRecord(ctx, toApiRecord(d)); err != nil {
+				t.Fatal(err)
+			}
+
+			listings.Store(0)
+			if diags := resourceDnsRecordRead(ctx, d, config); diags.HasError() || d.Id() != "" {
+				t.Fatalf("expected the deleted record to be removed from state, got %s %+v", d.Id(), diags)
+			}
+			if listings.Load() != c.listings {
+				t.Fatalf("expected %d listings of the zone, got %d", c.listings, listings.Load())
+			}
+		})
+	}
+}
+
+func TestDnsRecordUpdateChangesId(t *testing.T) {
+	api := newMemoryApi()
+	api.CreateZone(context.Background(), cloudns.Zone{Domain: "example.com", Ztype: "master"})
+	api.renumberOnUpdate = true
+	config := ClientConfig{client: api}
+
+	d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{
+		"name":  "www",
+		"zone":  "example.com",
+		"type":  "A",
+		"value": "1.2.3.4",
+		"ttl":   600,
+	})
+	if diags := resourceDnsRecordCreate(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+	created := d.Id()
+
+	d.Set("value", "5.6.7.8")
+	if diags := resourceDnsRecordUpdate(context.Background(), d, config); diags.HasError() {
+		t.Fatalf("update: %+v", diags)
+	}
+
+	if d.Id() == "" || d.Id() == created || d.Get("value") != "5.6.7.8" {
+		t.Fatalf("expected the state to follow the new ID of the record, got %s (was %s) %+v", d.Id(), created, d.State())
+	}
+}
+
+// recordTypeAttributes are the attributes each type of record is stored with besides its name, value and ttl
+var recordTypeAttributes = map[string][]string{
+	"A":          nil,
+	"AAAA":       nil,
+	"ALIAS":      nil,
+	"CAA":        {"caaflag", "caatype", "caavalue"},
+	"CERT":       {"certtype", "certkeytag", "certalgorithm"},
+	"CNAME":      nil,
+	"DNAME":      nil,
+	"DS":         {"keytag", "algorithm", "digesttype"},
+	"HINFO":      {"cpu", "os"},
+	"LOC":        {"latdeg", "latmin", "latsec", "latdir", "longdeg", "longmin", "longsec", "longdir", "altitude", "size", "hprecision", "vprecision"},
+	"MX":         {"priority"},
+	"NAPTR":      {"flag", "order", "pref", "params", "regexp", "replace"},
+	"NS":         nil,
+	"OPENPGPKEY": nil,
+	"PTR":        nil,
+	"RP":         {"mail", "txt"},
+	"SMIMEA":     {"smimeausage", "smimeaselector", "smimeamatchingtype"},
+	"SPF":        nil,
+	"SRV":        {"priority", "weight", "port"},
+	"SSHFP":      {"algorithm", "fptype"},
+	"TLSA":       {"tlsausage", "tlsaselector", "tlsamatchingtype"},
+	"TXT":        nil,
+	"WR":         {"frame", "frametitle", "framekeywords", "framedescription", "mobilemeta", "savepath", "redirecttype"},
+}
+
+// recordRoundTrip sends the record configured by raw the way the client does, reads it back from the listing
+// the way the client does and stores it in a fresh state
+func recordRoundTrip(t *testing.T, raw map[string]interface{}) (cloudns.Record, *schema.ResourceData) {
+	d := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, raw)
+	d.SetId("1")
+	sent := toApiRecord(d)
+
+	body, err := json.Marshal(toRecordRequest(sent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := map[string]interface{}{}
+	if err := json.Unmarshal(body, &listed); err != nil {
+		t.Fatal(err)
+	}
+	listed["id"] = sent.ID
+	listed["type"] = listed["record-type"]
+	delete(listed, "record-type")
+	delete(listed, "domain-name")
+	if body, err = json.Marshal(listed); err != nil {
+		t.Fatal(err)
+	}
+	var record apiRecord
+	if err := json.Unmarshal(body, &record); err != nil {
+		t.Fatal(err)
+	}
+	read := record.toRecord(sent.Domain)
+
+	state := schema.TestResourceDataRaw(t, resourceDnsRecord().Schema, map[string]interface{}{})
+	state.SetId(read.ID)
+	if err := updateState(state, &read); err != nil {
+		t.Fatal(err)
+	}
+	return sent, state
+}
+
+// checkRecordRoundTrip fails when the state read back differs from the configuration it was created from
+func checkRecordRoundTrip(t *testing.T, raw map[string]interface{}) bool {
+	t.Helper()
+
+	sent, state := recordRoundTrip(t, raw)
+	ok := true
+	for key, value := range raw {
+		if got := state.Get(key); got != value {
+			t.Errorf("%s record: expected %s to be %v, got %v", raw["type"], key, value, got)
+			ok = false
+		}
+	}
+	if stored := toApiRecord(state); !reflect.DeepEqual(stored, sent) {
+		t.Errorf("%s record: expected %+v to be stored, got %+v", raw["type"], sent, stored)
+		ok = false
+	}
+	return ok
+}
+
+func TestDnsRecordRoundTrip(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"A":          {"value": "1.2.3.4"},
+		"AAAA":       {"value": "2001:db8::1"},
+		"ALIAS":      {"value": "example.net"},
+		"CAA":        {"value": "", "caaflag": "0", "caatype": "issue", "caavalue": "letsencrypt.org"},
+		"CERT":       {"value": "MIIB", "certtype": 1, "certkeytag": 12345, "certalgorithm": 8},
+		"CNAME":      {"value": "example.net"},
+		"DNAME":      {"value": "example.net"},
+		"DS":         {"value": "2BB183AF5F22588179A53B0A98631FAD1A292118", "keytag": 60485, "algorithm": 5, "digesttype": 1},
+		"HINFO":      {"value": "", "cpu": "x86_64", "os": "linux"},
+		"LOC":        {"value": "", "latdeg": 42.0, "latmin": 21.0, "latsec": 54.5, "latdir": "N", "longdeg": 71.0, "longmin": 6.0, "longsec": 18.25, "longdir": "W", "altitude": "-24", "size": "30", "hprecision": "10", "vprecision": "2"},
+		"MX":         {"value": "mail.example.com", "priority": 10},
+		"NAPTR":      {"value": "", "flag": "U", "order": "100", "pref": "10", "params": "E2U+sip", "regexp": "!^.*$!sip:info@example.com!", "replace": ""},
+		"NS":         {"value": "ns1.example.net"},
+		"OPENPGPKEY": {"value": "mQINBFtest"},
+		"PTR":        {"value": "host.example.com"},
+		"RP":         {"value": "", "mail": "admin.example.com", "txt": "info.example.com"},
+		"SMIMEA":     {"value": "d2abde240d7cd3ee6b4b28c54df034b9", "smimeausage": "3", "smimeaselector": "1", "smimeamatchingtype": "1"},
+		"SPF":        {"value": "v=spf1 -all"},
+		"SRV":        {"value": "sip.example.com", "priority": 10, "weight": 60, "port": 5060},
+		"SSHFP":      {"value": "123456789abcdef67890123456789abcdef67890", "algorithm": 2, "fptype": 1},
+		"TLSA":       {"value": "d2abde240d7cd3ee6b4b28c54df034b9", "tlsausage": "3", "tlsaselector": "1", "tlsamatchingtype": "1"},
+		"TXT":        {"value": "v=DMARC1; p=none"},
+		"WR":         {"value": "https://example.net", "frame": "1", "frametitle": "Example", "framekeywords": "example", "framedescription": "An example", "mobilemeta": 1, "savepath": 1, "redirecttype": 302},
+	}
+
+	for rtype := range recordTypeAttributes {
+		if _, ok := cases[rtype]; !ok {
+			t.Errorf("no round trip case for %s records", rtype)
+		}
+	}
+
+	for rtype, attributes := range cases {
+		t.Run(rtype, func(t *testing.T) {
+			raw := map[string]interface{}{"name": "www", "zone": "example.com", "type": rtype, "ttl": 3600}
+			for key, value := range attributes {
+				raw[key] = value
+			}
+			checkRecordRoundTrip(t, raw)
+		})
+	}
+
+	t.Run("GeoDNS", func(t *testing.T) {
+		checkRecordRoundTrip(t, map[string]interface{}{
+			"name": "www", "zone": "example.com", "type": "A", "value": "1.2.3.4", "ttl": 3600,
+			"geodnslocation": "EU", "geodnscode": "BG",
+		})
+	})
+}
+
+// randomRecordConfig configures a record of the type with random values for all of its attributes
+func randomRecordConfig(random *rand.Rand, rtype string) map[string]interface{} {
+	str := func() string {
+		const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+		b := make([]byte, 1+random.Intn(16))
+		for i := range b {
+			b[i] = letters[random.Intn(len(letters))]
+		}
+		return string(b)
+	}
+
+	raw := map[string]interface{}{
+		"name":  str(),
+		"zone":  str() + ".com",
+		"type":  rtype,
+		"value": str(),
+		"ttl":   1 + random.Intn(1209600),
+	}
+
+	recordSchema := resourceDnsRecord().Schema
+	for _, key := range recordTypeAttributes[rtype] {
+		switch recordSchema[key].Type {
+		case schema.TypeInt:
+			raw[key] = 1 + random.Intn(65535)
+		case schema.TypeFloat:
+			raw[key] = float64(random.Intn(36000)) / 100
+		default:
+			raw[key] = str()
+		}
+	}
+	return raw
+}
+
+func TestDnsRecordRoundTripRandom(t *testing.T) {
+	for rtype := range recordTypeAttributes {
+		t.Run(rtype, func(t *testing.T) {
+			roundTrips := func(seed int64) bool {
+				return checkRecordRoundTrip(t, randomRecordConfig(rand.New(rand.NewSource(seed)), rtype))
+			}
+			if err := quick.Check(roundTrips, &quick.Config{MaxCount: 50}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
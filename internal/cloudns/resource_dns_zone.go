@@ -2,10 +2,12 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -22,6 +24,12 @@ func resourceDnsZone() *schema.Resource {
 		UpdateContext: resourceDnsZoneUpdate,
 		DeleteContext: resourceDnsZoneDelete,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceDnsZoneImport,
 		},
@@ -82,12 +90,12 @@ func resourceDnsZoneCreate(ctx context.Context, d *schema.ResourceData, meta int
 	_, nsExist := d.GetOk("nameservers")
 	_, nstExist := d.GetOk("nameserver_type")
 	if nsExist || nstExist {
-		nsList, _ := cloudns.Ns{}.List(clientConfig.apiAccess)
+		nsList, _ := clientConfig.client.ListNameservers(ctx)
 		zoneToCreate.Ns = getNsNames(d, nsList)
 		d.Set("nameservers", zoneToCreate.Ns)
 	}
 
-	resp, err := zoneToCreate.Create(&clientConfig.apiAccess)
+	resp, err := clientConfig.client.CreateZone(ctx, zoneToCreate)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -106,10 +114,9 @@ func resourceDnsZoneRead(ctx context.Context, d *schema.ResourceData, meta inter
 	clientConfig := meta.(ClientConfig)
 	zoneToRead := toApiZone(d)
 
-	clientConfig.rateLimiter.Take()
-	zoneRead, err := zoneToRead.Read(&clientConfig.apiAccess)
+	zoneRead, err := clientConfig.client.ReadZone(ctx, zoneToRead)
 	if err != nil {
-		if isNotFoundErr(err) {
+		if errors.Is(err, errNotFound) {
 			tflog.Warn(ctx, fmt.Sprintf("DNS zone not found: %s. Removing from state.", zoneToRead.Domain))
 			d.SetId("")
 			return nil
@@ -123,7 +130,7 @@ func resourceDnsZoneRead(ctx context.Context, d *schema.ResourceData, meta inter
 		return nil
 	}
 
-	nsRecords, err := getFilteredZoneRecords(zoneToRead, clientConfig, []string{"NS"})
+	nsRecords, err := getFilteredZoneRecords(ctx, zoneToRead, clientConfig, "NS")
 	if err == nil && len(nsRecords) > 0 {
 		zoneRead.Ns = sortNsNames(nsRecords)
 	}
@@ -143,7 +150,7 @@ func resourceDnsZoneDelete(ctx context.Context, d *schema.ResourceData, meta int
 	clientConfig := meta.(ClientConfig)
 	zoneToDelete := toApiZone(d)
 
-	resp, err := zoneToDelete.Destroy(&clientConfig.apiAccess)
+	resp, err := clientConfig.client.DeleteZone(ctx, zoneToDelete)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -158,9 +165,8 @@ func resourceDnsZoneImport(ctx context.Context, d *schema.ResourceData, meta int
 	clientConfig := meta.(ClientConfig)
 	domain := d.Id()
 
-	clientConfig.rateLimiter.Take()
 	zoneToRead := cloudns.Zone{Domain: domain}
-	zoneRead, err := zoneToRead.Read(&clientConfig.apiAccess)
+	zoneRead, err := clientConfig.client.ReadZone(ctx, zoneToRead)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +175,7 @@ func resourceDnsZoneImport(ctx context.Context, d *schema.ResourceData, meta int
 		return nil, fmt.Errorf("Zone not found: %#v", domain)
 	}
 
-	nsRecords, err := getFilteredZoneRecords(zoneToRead, clientConfig, []string{"NS"})
+	nsRecords, err := getFilteredZoneRecords(ctx, zoneToRead, clientConfig, "NS")
 	if err == nil && len(nsRecords) > 0 {
 		zoneRead.Ns = sortNsNames(nsRecords)
 	}
@@ -248,18 +254,16 @@ func getNsNames(d *schema.ResourceData, nsList []cloudns.Ns) []string {
 	return fns
 }
 
-func getFilteredZoneRecords(z cloudns.Zone, c ClientConfig, filter []string) ([]string, error) {
-	// TODO: functionality should be moved to the `cloudns-go` repository
-	zoneRecords, err := z.List(&c.apiAccess)
+// getFilteredZoneRecords returns the values of the records of type rtype, filtered by ClouDNS
+func getFilteredZoneRecords(ctx context.Context, z cloudns.Zone, c ClientConfig, rtype string) ([]string, error) {
+	zoneRecords, err := c.client.FindRecords(ctx, z.Domain, recordFilter{rtype: rtype})
 	if err != nil && len(zoneRecords) == 0 {
 		return nil, fmt.Errorf("found no records zone for %s", z.Domain)
 	}
 
 	var rns []string
 	for _, rec := range zoneRecords {
-		if len(filter) == 0 || slices.Contains(filter, rec.Rtype) {
-			rns = append(rns, rec.Record)
-		}
+		rns = append(rns, rec.Record)
 	}
 
 	return rns, nil
@@ -277,7 +281,3 @@ func toApiZone(d *schema.ResourceData) cloudns.Zone {
 		Ns:     []string{},
 	}
 }
-
-func isNotFoundErr(err error) bool {
-	return strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no zones returned")
-}
@@ -1,56 +1,54 @@
 package cloudns
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccDnsZone_basic(t *testing.T) {
-	testUuid := uuid.NewString()
+	testDomain := fmt.Sprintf("%s.com", uuid.NewString())
 
 	resource.UnitTest(t, resource.TestCase{
 		PreCheck:          func() { testAccPreCheck(t) },
 		ProviderFactories: providerFactories,
 		Steps: []resource.TestStep{
 			{
-				Config: zone("some-zone", testUuid),
-				Check:  checkZone("some-zone", testUuid),
+				Config: zone("some-zone", testDomain),
+				Check:  checkZone("some-zone", testDomain),
 			},
 		},
 		CheckDestroy: CheckDestroyedZones,
 	})
 }
 
-func zone(resourceName string, name string) string {
+func zone(resourceName string, domain string) string {
 	return fmt.Sprintf(`
 resource "cloudns_dns_zone" "%s" {
-    name = "%s"
+    domain = "%s"
     type = "master"
 }
-`, resourceName, name)
+`, resourceName, domain)
 }
 
-func checkZone(resourceName string, name string) resource.TestCheckFunc {
+func checkZone(resourceName string, domain string) resource.TestCheckFunc {
 	path := fmt.Sprintf("cloudns_dns_zone.%s", resourceName)
 	return resource.ComposeTestCheckFunc(
-		resource.TestCheckResourceAttr(path, "name", name),
+		resource.TestCheckResourceAttr(path, "domain", domain),
 		resource.TestCheckResourceAttr(path, "type", "master"),
 	)
 }
 
 func CheckDestroyedZones(state *terraform.State) error {
 	provider := testAccProvider
-	apiAccess := provider.Meta().(ClientConfig).apiAccess
-	zones, err := cloudns.Zone{}.List(&apiAccess)
-
-	if err != nil {
-		return err
-	}
+	client := provider.Meta().(ClientConfig).client
 
 	for _, rs := range state.RootModule().Resources {
 		if rs.Type != "cloudns_dns_zone" {
@@ -59,16 +57,66 @@ func CheckDestroyedZones(state *terraform.State) error {
 
 		fmt.Printf("Checking that cloudns_dns_zone#%s was properly deleted\n", rs.Primary.ID)
 
-		for _, zone := range zones {
-			existingZoneId := zone.ID
-			if rs.Primary.ID == existingZoneId {
-				return fmt.Errorf(
-					"zone %s still exists",
-					zone.ID,
-				)
-			}
+		_, err := client.ReadZone(context.Background(), cloudns.Zone{Domain: rs.Primary.ID})
+		if err == nil {
+			return fmt.Errorf("zone %s still exists", rs.Primary.ID)
+		}
+		if !errors.Is(err, errNotFound) {
+			return err
 		}
 	}
 
 	return nil
 }
+
+func TestDnsZoneLifecycleAgainstFakeApi(t *testing.T) {
+	config := newFakeApi(t).ClientConfig(t)
+	ctx := context.Background()
+
+	d := schema.TestResourceDataRaw(t, resourceDnsZone().Schema, map[string]interface{}{
+		"domain":          "example.com",
+		"type":            "master",
+		"nameserver_type": "premium",
+	})
+
+	if diags := resourceDnsZoneCreate(ctx, d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+	if d.Id() != "example.com" || d.Get("type") != "master" {
+		t.Fatalf("expected the created zone in state, got %s %+v", d.Id(), d.State())
+	}
+	if ns := d.Get("nameservers").([]interface{}); len(ns) != 2 || ns[0] != "pns1.fake-cloudns.net" || ns[1] != "pns2.fake-cloudns.net" {
+		t.Fatalf("expected the premium nameservers, got %+v", ns)
+	}
+
+	if diags := resourceDnsZoneCreate(ctx, d, config); !diags.HasError() {
+		t.Fatal("expected registering the zone twice to fail")
+	}
+
+	if diags := resourceDnsZoneDelete(ctx, d, config); diags.HasError() {
+		t.Fatalf("delete: %+v", diags)
+	}
+
+	d.SetId("example.com")
+	if diags := resourceDnsZoneRead(ctx, d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected the deleted zone to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestDnsZoneReadNotFound(t *testing.T) {
+	config := ClientConfig{client: newMemoryApi()}
+
+	d := schema.TestResourceDataRaw(t, resourceDnsZone().Schema, map[string]interface{}{
+		"domain": "example.com",
+		"type":   "master",
+	})
+	d.SetId("example.com")
+
+	if diags := resourceDnsZoneRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a zone deleted outside of Terraform to be removed from state, got %s %+v", d.Id(), diags)
+	}
+
+	if _, err := resourceDnsZoneImport(context.Background(), d, config); !errors.Is(err, errNotFound) {
+		t.Fatalf("expected importing a missing zone to fail, got %v", err)
+	}
+}
@@ -2,7 +2,10 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -14,10 +17,22 @@ func resourceDynamicUrl() *schema.Resource {
 	return &schema.Resource{
 		Description: "A dynamic URL for an A or AAAA record managed by ClouDNS.",
 
-		CreateContext: resourceDynamicUrlGetOrCreate,
-		ReadContext:   resourceDynamicUrlGetOrCreate,
+		CreateContext: resourceDynamicUrlCreate,
+		ReadContext:   resourceDynamicUrlRead,
+		UpdateContext: resourceDynamicUrlUpdate,
 		DeleteContext: resourceDynamicUrlDelete,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDynamicUrlImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"domain": {
 				Description: "The name of the DNS zone.",
@@ -31,26 +46,55 @@ func resourceDynamicUrl() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			"keepers": {
+				Description: "Arbitrary map of values which, when changed, rotate the URL. Use it to regenerate a leaked URL or to rotate it on a schedule.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"url": {
-				Description: "The URL to which the dynamic DNS request will be sent.",
+				Description: "The URL to which the dynamic DNS request will be sent. Anyone knowing it can change the record, so treat it as a credential.",
 				Type:        schema.TypeString,
 				Computed:    true,
+				Sensitive:   true,
 			},
 		},
 	}
 }
 
-func resourceDynamicUrlGetOrCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceDynamicUrlCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	config := meta.(ClientConfig)
 	dynUrl := toApiDynamicUrl(d)
 
-	tflog.Debug(ctx, fmt.Sprintf("Dynamic URL object before read: %+v", dynUrl))
+	tflog.Debug(ctx, fmt.Sprintf("CREATE dynamic URL #%s for Domain: %s", dynUrl.RecordId, dynUrl.Domain))
 
-	config.rateLimiter.Take()
+	createdDynUrl, err := config.client.ReadOrCreateDynamicUrl(ctx, dynUrl)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	readDynUrl, err := dynUrl.ReadOrCreate(&config.apiAccess)
+	d.SetId(createdDynUrl.RecordId)
+
+	err = updateDynamicUrlState(d, &createdDynUrl)
 	if err != nil {
-		if isNotFoundError(err) {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceDynamicUrlRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	dynUrl := toApiDynamicUrl(d)
+
+	tflog.Debug(ctx, fmt.Sprintf("READ dynamic URL #%s for Domain: %s", dynUrl.RecordId, dynUrl.Domain))
+
+	readDynUrl, err := config.client.ReadDynamicUrl(ctx, dynUrl)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			tflog.Warn(ctx, fmt.Sprintf("Dynamic URL #%s for Domain: %s not found. Removing from state.", dynUrl.RecordId, dynUrl.Domain))
 			d.SetId("")
 			return nil
 		}
@@ -58,9 +102,6 @@ func resourceDynamicUrlGetOrCreate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	tflog.Debug(ctx, fmt.Sprintf("Dynamic URL object after read: %+v", readDynUrl))
-	d.SetId(readDynUrl.RecordId)
-
 	err = updateDynamicUrlState(d, &readDynUrl)
 	if err != nil {
 		return diag.FromErr(err)
@@ -71,15 +112,36 @@ func resourceDynamicUrlGetOrCreate(ctx context.Context, d *schema.ResourceData,
 	return nil
 }
 
+func resourceDynamicUrlUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	dynUrl := toApiDynamicUrl(d)
+
+	if !d.HasChange("keepers") {
+		return resourceDynamicUrlRead(ctx, d, meta)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("ROTATE dynamic URL #%s for Domain: %s", dynUrl.RecordId, dynUrl.Domain))
+
+	changedDynUrl, err := config.client.ChangeDynamicUrl(ctx, dynUrl)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = updateDynamicUrlState(d, &changedDynUrl)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
 func resourceDynamicUrlDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	config := meta.(ClientConfig)
 	dynUrl := toApiDynamicUrl(d)
 
 	tflog.Debug(ctx, fmt.Sprintf("DELETE dynamic URL #%s for Domain: %s", dynUrl.RecordId, dynUrl.Domain))
 
-	config.rateLimiter.Take()
-
-	_, err := dynUrl.Delete(&config.apiAccess)
+	err := config.client.DeleteDynamicUrl(ctx, dynUrl)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -89,6 +151,35 @@ func resourceDynamicUrlDelete(ctx context.Context, d *schema.ResourceData, meta
 	return nil
 }
 
+func resourceDynamicUrlImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(ClientConfig)
+
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Bad ID format: %#v. Expected: \"zone/recordid\"", d.Id())
+	}
+
+	dynUrl := cloudns.DynamicUrl{
+		Domain:   parts[0],
+		RecordId: parts[1],
+	}
+
+	readDynUrl, err := config.client.ReadDynamicUrl(ctx, dynUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateDynamicUrlState(d, &readDynUrl)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(readDynUrl.RecordId)
+
+	tflog.Debug(ctx, fmt.Sprintf("IMPORT dynamic URL #%s for Domain: %s", dynUrl.RecordId, dynUrl.Domain))
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func toApiDynamicUrl(d *schema.ResourceData) cloudns.DynamicUrl {
 	domain := d.Get("domain").(string)
 	recordId := d.Get("recordid").(string)
@@ -0,0 +1,137 @@
+package cloudns
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDynamicUrlImportBadId(t *testing.T) {
+	for _, id := range []string{"123456789", "example.com/123/456"} {
+		d := schema.TestResourceDataRaw(t, resourceDynamicUrl().Schema, map[string]interface{}{})
+		d.SetId(id)
+
+		if _, err := resourceDynamicUrlImport(context.Background(), d, ClientConfig{}); err == nil {
+			t.Errorf("expected %#v to be rejected", id)
+		}
+	}
+}
+
+func TestDynamicUrlIsNotLogged(t *testing.T) {
+	if !resourceDynamicUrl().Schema["url"].Sensitive {
+		t.Fatal("expected url to be sensitive")
+	}
+
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+
+	provider := schema.TestResourceDataRaw(t, New()().Schema, map[string]interface{}{
+		"auth_id":            fakeApiAuthId,
+		"password":           fakeApiPassword,
+		"api_endpoint":       api.URL,
+		"rate_limit":         1000,
+		"http_debug_logging": true,
+	})
+	meta, diags := configure()(context.Background(), provider)
+	if diags.HasError() {
+		t.Fatalf("configure: %+v", diags)
+	}
+	config := meta.(ClientConfig)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	record, err := config.client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "home", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceDynamicUrl().Schema, map[string]interface{}{
+		"domain":   "example.com",
+		"recordid": record.ID,
+		"keepers":  map[string]interface{}{"rotation": "1"},
+	})
+	var tokens []string
+	for _, step := range []func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics{
+		resourceDynamicUrlCreate, resourceDynamicUrlRead, resourceDynamicUrlUpdate,
+	} {
+		if diags := step(ctx, d, config); diags.HasError() {
+			t.Fatalf("%+v", diags)
+		}
+		_, token, _ := strings.Cut(d.Get("url").(string), "?q=")
+		tokens = append(tokens, token)
+	}
+
+	logged := output.String()
+	if !strings.Contains(logged, "/dns/get-dynamic-url.json") || !strings.Contains(logged, "/dns/change-dynamic-url.json") {
+		t.Fatalf("expected the calls to be logged, got %s", logged)
+	}
+	for _, token := range tokens {
+		if token == "" || strings.Contains(logged, token) {
+			t.Errorf("expected the dynamic URL token %q to be masked, got %s", token, logged)
+		}
+	}
+}
+
+func TestDynamicUrlLifecycleAgainstFakeApi(t *testing.T) {
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	config := api.ClientConfig(t)
+	ctx := context.Background()
+
+	record, err := config.client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "home", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceDynamicUrl().Schema, map[string]interface{}{
+		"domain":   "example.com",
+		"recordid": record.ID,
+		"keepers":  map[string]interface{}{"rotation": "1"},
+	})
+
+	if diags := resourceDynamicUrlCreate(ctx, d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+	url := d.Get("url").(string)
+	if d.Id() != record.ID || !strings.Contains(url, "dynamicURL") {
+		t.Fatalf("expected the dynamic URL in state, got %s %+v", d.Id(), d.State())
+	}
+
+	// keepers are not in the state yet, which is a change
+	if diags := resourceDynamicUrlUpdate(ctx, d, config); diags.HasError() {
+		t.Fatalf("update: %+v", diags)
+	}
+	if rotated := d.Get("url").(string); rotated == url || rotated == "" {
+		t.Fatalf("expected the dynamic URL to be rotated, got %s", rotated)
+	}
+
+	if diags := resourceDynamicUrlDelete(ctx, d, config); diags.HasError() {
+		t.Fatalf("delete: %+v", diags)
+	}
+
+	d.SetId(record.ID)
+	if diags := resourceDynamicUrlRead(ctx, d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected the disabled dynamic URL to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestDynamicUrlReadNotFound(t *testing.T) {
+	config := ClientConfig{client: newMemoryApi()}
+
+	d := schema.TestResourceDataRaw(t, resourceDynamicUrl().Schema, map[string]interface{}{
+		"domain":   "example.com",
+		"recordid": "123456789",
+	})
+	d.SetId("123456789")
+
+	if diags := resourceDynamicUrlRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a dynamic URL disabled outside of Terraform to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
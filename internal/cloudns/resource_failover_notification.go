@@ -0,0 +1,275 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var notificationTypes = []string{"mail", "sms", "webhook"}
+
+func resourceFailoverNotification() *schema.Resource {
+	return &schema.Resource{
+		Description: "The complete list of notification channels of a DNS failover record managed by ClouDNS.",
+
+		CreateContext: resourceFailoverNotificationCreate,
+		ReadContext:   resourceFailoverNotificationRead,
+		UpdateContext: resourceFailoverNotificationUpdate,
+		DeleteContext: resourceFailoverNotificationDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceFailoverNotificationImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Description: "The name of the DNS zone.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"recordid": {
+				Description: "The ID of the record on which the failover is activated.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"notification": {
+				Description: "A notification channel. Channels which exist on ClouDNS but are not listed here are removed.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description:      fmt.Sprintf("The type of the channel, one of %s.", strings.Join(notificationTypes, ", ")),
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(notificationTypes, false)),
+						},
+						"value": {
+							Description:      "The e-mail address, phone number or webhook URL to notify.",
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type failoverNotification struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (n failoverNotification) key() string {
+	return n.Type + "/" + n.Value
+}
+
+func resourceFailoverNotificationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	domain := d.Get("domain").(string)
+	recordId := d.Get("recordid").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("CREATE Failover notifications for #%s in %s", recordId, domain))
+
+	err := reconcileFailoverNotifications(ctx, config, domain, recordId, toApiFailoverNotifications(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", domain, recordId))
+
+	return resourceFailoverNotificationRead(ctx, d, meta)
+}
+
+func resourceFailoverNotificationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	domain := d.Get("domain").(string)
+	recordId := d.Get("recordid").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("READ Failover notifications for #%s in %s", recordId, domain))
+
+	notifications, err := config.client.ListFailoverNotifications(ctx, domain, recordId)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			tflog.Warn(ctx, fmt.Sprintf("Failover #%s in %s not found. Removing notifications from state.", recordId, domain))
+			d.SetId("")
+			return nil
+		}
+
+		return diag.FromErr(err)
+	}
+
+	err = updateFailoverNotificationState(d, notifications)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceFailoverNotificationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	domain := d.Get("domain").(string)
+	recordId := d.Get("recordid").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("UPDATE Failover notifications for #%s in %s", recordId, domain))
+
+	err := reconcileFailoverNotifications(ctx, config, domain, recordId, toApiFailoverNotifications(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceFailoverNotificationRead(ctx, d, meta)
+}
+
+func resourceFailoverNotificationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	domain := d.Get("domain").(string)
+	recordId := d.Get("recordid").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("DELETE Failover notifications for #%s in %s", recordId, domain))
+
+	err := reconcileFailoverNotifications(ctx, config, domain, recordId, nil)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func resourceFailoverNotificationImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(ClientConfig)
+
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Bad ID format: %#v. Expected: \"zone/recordid\"", d.Id())
+	}
+	domain := parts[0]
+	recordId := parts[1]
+
+	notifications, err := config.client.ListFailoverNotifications(ctx, domain, recordId)
+	if err != nil {
+		return nil, err
+	}
+	// the resource requires at least one notification, an empty list would be planned to be created anew
+	if len(notifications) == 0 {
+		return nil, fmt.Errorf("Failover #%s in %s has no notifications to import, create the resource instead", recordId, domain)
+	}
+
+	if err := d.Set("domain", domain); err != nil {
+		return nil, err
+	}
+	if err := d.Set("recordid", recordId); err != nil {
+		return nil, err
+	}
+	if err := updateFailoverNotificationState(d, notifications); err != nil {
+		return nil, err
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("IMPORT Failover notifications for #%s in %s", recordId, domain))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// reconcileFailoverNotifications adds and removes notifications on ClouDNS until they match the wanted ones
+func reconcileFailoverNotifications(ctx context.Context, config ClientConfig, domain string, recordId string, wanted []failoverNotification) error {
+	current, err := config.client.ListFailoverNotifications(ctx, domain, recordId)
+	if err != nil {
+		return err
+	}
+
+	toAdd, toRemove := diffFailoverNotifications(current, wanted)
+
+	for _, n := range toRemove {
+		tflog.Debug(ctx, fmt.Sprintf("Removing %s notification %s from failover #%s", n.Type, n.Value, recordId))
+
+		err := config.client.DeleteFailoverNotification(ctx, domain, recordId, n)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, n := range toAdd {
+		tflog.Debug(ctx, fmt.Sprintf("Adding %s notification %s to failover #%s", n.Type, n.Value, recordId))
+
+		err := config.client.AddFailoverNotification(ctx, domain, recordId, n)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffFailoverNotifications returns the notifications missing from current and the ones which are no longer wanted.
+// Notifications are compared by type and value, as the IDs are only known for the current ones.
+func diffFailoverNotifications(current []failoverNotification, wanted []failoverNotification) (toAdd []failoverNotification, toRemove []failoverNotification) {
+	currentKeys := make(map[string]bool, len(current))
+	for _, n := range current {
+		currentKeys[n.key()] = true
+	}
+
+	wantedKeys := make(map[string]bool, len(wanted))
+	for _, n := range wanted {
+		wantedKeys[n.key()] = true
+		if !currentKeys[n.key()] {
+			toAdd = append(toAdd, n)
+			// guard against the same notification being listed twice
+			currentKeys[n.key()] = true
+		}
+	}
+
+	for _, n := range current {
+		if !wantedKeys[n.key()] {
+			toRemove = append(toRemove, n)
+		}
+	}
+
+	return toAdd, toRemove
+}
+
+func toApiFailoverNotifications(d *schema.ResourceData) []failoverNotification {
+	var notifications []failoverNotification
+	for _, raw := range d.Get("notification").(*schema.Set).List() {
+		n := raw.(map[string]interface{})
+		notifications = append(notifications, failoverNotification{
+			Type:  n["type"].(string),
+			Value: n["value"].(string),
+		})
+	}
+
+	return notifications
+}
+
+func updateFailoverNotificationState(d *schema.ResourceData, notifications []failoverNotification) error {
+	var rns []interface{}
+	for _, n := range notifications {
+		rns = append(rns, map[string]interface{}{
+			"type":  n.Type,
+			"value": n.Value,
+		})
+	}
+
+	return d.Set("notification", rns)
+}
@@ -0,0 +1,118 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDiffFailoverNotifications(t *testing.T) {
+	current := []failoverNotification{
+		{ID: "1", Type: "mail", Value: "oncall-march@example.com"},
+		{ID: "2", Type: "webhook", Value: "https://hooks.example.com/failover"},
+		{ID: "3", Type: "sms", Value: "+15555550100"},
+	}
+	wanted := []failoverNotification{
+		{Type: "mail", Value: "oncall-april@example.com"},
+		{Type: "webhook", Value: "https://hooks.example.com/failover"},
+		{Type: "mail", Value: "oncall-april@example.com"},
+	}
+
+	toAdd, toRemove := diffFailoverNotifications(current, wanted)
+
+	if len(toAdd) != 1 || toAdd[0].key() != "mail/oncall-april@example.com" {
+		t.Fatalf("bad notifications to add: %+v", toAdd)
+	}
+
+	if len(toRemove) != 2 || toRemove[0].ID != "1" || toRemove[1].ID != "3" {
+		t.Fatalf("bad notifications to remove: %+v", toRemove)
+	}
+}
+
+func TestDiffFailoverNotificationsRemoveAll(t *testing.T) {
+	current := []failoverNotification{
+		{ID: "1", Type: "mail", Value: "oncall@example.com"},
+	}
+
+	toAdd, toRemove := diffFailoverNotifications(current, nil)
+
+	if len(toAdd) != 0 {
+		t.Fatalf("expected nothing to add, got: %+v", toAdd)
+	}
+
+	if len(toRemove) != 1 || toRemove[0].ID != "1" {
+		t.Fatalf("bad notifications to remove: %+v", toRemove)
+	}
+}
+
+func TestDecodeApiList(t *testing.T) {
+	for name, body := range map[string]string{
+		"array": `[{"id":"7","type":"mail","value":"oncall@example.com"}]`,
+		"keyed": `{"7":{"id":"7","type":"mail","value":"oncall@example.com"}}`,
+	} {
+		notifications, err := decodeApiList[failoverNotification](json.RawMessage(body))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if len(notifications) != 1 || notifications[0].ID != "7" || notifications[0].Value != "oncall@example.com" {
+			t.Fatalf("%s: bad notifications: %+v", name, notifications)
+		}
+	}
+}
+
+func TestFailoverNotificationReadNotFound(t *testing.T) {
+	config := ClientConfig{client: newMemoryApi()}
+
+	d := schema.TestResourceDataRaw(t, resourceFailoverNotification().Schema, map[string]interface{}{
+		"domain":   "example.com",
+		"recordid": "123456789",
+	})
+	d.SetId("example.com/123456789")
+
+	if diags := resourceFailoverNotificationRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected the notifications of a deactivated failover to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestFailoverNotificationImportWithoutNotifications(t *testing.T) {
+	ctx := context.Background()
+	api := newMemoryApi()
+	api.CreateZone(ctx, cloudns.Zone{Domain: "example.com", Ztype: "master"})
+	config := ClientConfig{client: api}
+
+	record, err := api.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failover := apiFailover{}
+	failover.Domain = "example.com"
+	failover.RecordId = record.ID
+	failover.FailoverType = checkTypePing
+	failover.MainIP = "1.2.3.4"
+	if _, err := api.CreateFailover(ctx, failover); err != nil {
+		t.Fatal(err)
+	}
+
+	d := resourceFailoverNotification().Data(nil)
+	d.SetId("example.com/" + record.ID)
+
+	_, err = resourceFailoverNotificationImport(ctx, d, config)
+	if err == nil || !strings.Contains(err.Error(), "has no notifications") {
+		t.Fatalf("expected the import to be rejected, got %v", err)
+	}
+
+	if err := api.AddFailoverNotification(ctx, "example.com", record.ID, failoverNotification{Type: "mail", Value: "oncall@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resourceFailoverNotificationImport(ctx, d, config); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Get("notification").(*schema.Set).Len(); n != 1 {
+		t.Fatalf("expected the notification to be imported, got %d", n)
+	}
+}
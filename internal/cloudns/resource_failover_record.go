@@ -2,15 +2,27 @@ package cloudns
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ClouDNS/cloudns-go"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+var failoverWebhookMethods = []string{"GET", "POST", "PUT"}
+
+// failoverWebhookEvents maps the webhook blocks to the event names used by the ClouDNS API
+var failoverWebhookEvents = map[string]string{
+	"down_webhook": "down",
+	"up_webhook":   "up",
+}
+
 func resourceDnsFailover() *schema.Resource {
 	return &schema.Resource{
 		Description: "A DNS failover record managed by ClouDNS.",
@@ -20,153 +32,136 @@ func resourceDnsFailover() *schema.Resource {
 		UpdateContext: resourceDnsFailoverUpdate,
 		DeleteContext: resourceDnsFailoverDelete,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: resourceCheckValidate,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceDnsFailoverImport,
 		},
 
-		Schema: map[string]*schema.Schema{
-			"domain": {
-				Description: "The name of the DNS zone.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-			},
-			"recordid": {
-				Description: "The ID of the record for which the failover to be activated / the same as the id param",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-			},
-			"checktype": {
-				Description: "Monitoring check types for this Failover.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    false,
-			},
-			"downeventhandler": {
-				Description: "Event handler if Main IP is down.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    false,
-			},
-			"upeventhandler": {
-				Description: "Event handler if Main IP is up.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    false,
-			},
-			"mainip": {
-				Description: "Main IP address which will be monitored.",
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    false,
-			},
-			"backupip1": {
-				Description: "First Backup IP address.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"backupip2": {
-				Description: "Second Backup IP address.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"backupip3": {
-				Description: "Third Backup IP address.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"backupip4": {
-				Description: "Fourth Backup IP address.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"backupip5": {
-				Description: "Fifth Backup IP address.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"monitoringregion": {
-				Description: "Monitoring region or country.",
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    false,
-			},
-			"checkperiod": {
-				Description: "Time-frame between each monitoring check.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"notificationmail": {
-				Description: "Email notifications settings.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"host": {
-				Description: "A host to query.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"port": {
-				Description: "A port to query.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"path": {
-				Description: "Path for the URL",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"content": {
-				Description: "Parameter required for Custom HTTP and Custom HTTPS check types",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"querytype": {
-				Description: "Parameter required for DNS check type. It must contain the record type (e.g. A).",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"queryresponse": {
-				Description: "Parameter required for DNS check type. You must fill in the response of the DNS server for this specific record.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"latencylimit": {
-				Description: "Only for Ping monitoring checks. If the latency of the check is above the limit, the check will be marked as DOWN.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"timeout": {
-				Description: "Only for Ping monitoring checks. Seconds to wait for a response. Must be between 1 and 5. Default value is 2.",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"checkregion": {
-				Description: "The region from which the check is monitored(it is only received from API)",
-				Type:        schema.TypeString,
-				Optional:    true,
-			},
-			"httprequesttype": {
-				Description: "Only for HTTP/S checks. The request type will be used for the check. The default value is GET. Possible values:",
-				Type:        schema.TypeString,
-				Optional:    true,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceDnsFailoverV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceDnsFailoverStateUpgradeV0,
 			},
 		},
+
+		Schema: resourceDnsFailoverSchema(),
 	}
 }
 
+func resourceDnsFailoverSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"domain": {
+			Description: "The name of the DNS zone.",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+		"recordid": {
+			Description: "The ID of the record for which the failover to be activated / the same as the id param",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+		},
+		"checktype": {
+			Description: "Monitoring check types for this Failover.",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    false,
+		},
+		"downeventhandler": {
+			Description:   "Event handler if Main IP is down. Passed to ClouDNS as is, use `down_webhook` to call a webhook instead.",
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      false,
+			ConflictsWith: []string{"down_webhook"},
+			Deprecated:    "Use the `down_webhook` block instead.",
+		},
+		"upeventhandler": {
+			Description:   "Event handler if Main IP is up. Passed to ClouDNS as is, use `up_webhook` to call a webhook instead.",
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      false,
+			ConflictsWith: []string{"up_webhook"},
+			Deprecated:    "Use the `up_webhook` block instead.",
+		},
+		"down_webhook": failoverWebhookSchema("Webhook called when the Main IP goes down."),
+		"up_webhook":   failoverWebhookSchema("Webhook called when the Main IP comes back up."),
+		"mainip": {
+			Description: "Main IP address which will be monitored.",
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    false,
+		},
+		"backupip1": {
+			Description: "First Backup IP address.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"backupip2": {
+			Description: "Second Backup IP address.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"backupip3": {
+			Description: "Third Backup IP address.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"backupip4": {
+			Description: "Fourth Backup IP address.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"backupip5": {
+			Description: "Fifth Backup IP address.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"checkregion": {
+			Description: "The region from which the check is monitored(it is only received from API)",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+	}
+
+	for k, v := range checkSchema() {
+		s[k] = v
+	}
+
+	// the notifications of a failover may be managed by cloudns_failover_notification instead
+	s["notificationmail"] = &schema.Schema{
+		Description: "Email notifications settings. Left as it is on ClouDNS when not set, e.g. when the notifications are managed by `cloudns_failover_notification`.",
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+	}
+
+	return s
+}
+
 func resourceDnsFailoverCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientConfig := meta.(ClientConfig)
 	failoverToCreate := toApiFailover(d)
-	tflog.Debug(ctx, fmt.Sprintf("Failover data to create: %+v", failoverToCreate))
+	tflog.Debug(ctx, fmt.Sprintf("CREATE Failover #%s for Domain: %s", failoverToCreate.RecordId, failoverToCreate.Domain))
 
-	resp, err := failoverToCreate.Create(&clientConfig.apiAccess)
+	_, err := clientConfig.client.CreateFailover(ctx, failoverToCreate)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	tflog.Debug(ctx, fmt.Sprintf("Response from Create API: %+v", resp))
+	err = syncFailoverWebhooks(ctx, clientConfig, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	return resourceDnsFailoverRead(ctx, d, meta)
 }
@@ -177,8 +172,12 @@ func resourceDnsFailoverUpdate(ctx context.Context, d *schema.ResourceData, meta
 
 	tflog.Debug(ctx, fmt.Sprintf("Update Failover for Domain: %s", failover.Domain))
 
-	config.rateLimiter.Take()
-	_, err := failover.Update(&config.apiAccess)
+	_, err := config.client.UpdateFailover(ctx, failover)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = syncFailoverWebhooks(ctx, config, d)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -190,13 +189,12 @@ func resourceDnsFailoverRead(ctx context.Context, d *schema.ResourceData, meta i
 	config := meta.(ClientConfig)
 	failover := toApiFailover(d)
 
-	tflog.Debug(ctx, fmt.Sprintf("Failover object before read: %+v", failover))
-
-	config.rateLimiter.Take()
+	// the failover is not logged as a whole, its webhooks may carry tokens
+	tflog.Debug(ctx, fmt.Sprintf("READ Failover #%s for Domain: %s", failover.RecordId, failover.Domain))
 
-	readFailover, err := failover.Read(&config.apiAccess)
+	readFailover, err := config.client.ReadFailover(ctx, failover)
 	if err != nil {
-		if isNotFoundError(err) {
+		if errors.Is(err, errNotFound) {
 			d.SetId("")
 			return nil
 		}
@@ -204,7 +202,6 @@ func resourceDnsFailoverRead(ctx context.Context, d *schema.ResourceData, meta i
 		return diag.FromErr(err)
 	}
 
-	tflog.Debug(ctx, fmt.Sprintf("Failover object after read: %+v", readFailover))
 	d.SetId(readFailover.RecordId)
 
 	err = updateFailoverState(d, &readFailover)
@@ -212,6 +209,11 @@ func resourceDnsFailoverRead(ctx context.Context, d *schema.ResourceData, meta i
 		return diag.FromErr(err)
 	}
 
+	err = readFailoverWebhooks(ctx, config, d, readFailover.Domain, readFailover.RecordId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	tflog.Debug(ctx, fmt.Sprintf("Failover state set successfully"))
 
 	return nil
@@ -223,9 +225,7 @@ func resourceDnsFailoverDelete(ctx context.Context, d *schema.ResourceData, meta
 
 	tflog.Debug(ctx, fmt.Sprintf("DELETE Failover #%s for Domain: %s", failover.RecordId, failover.Domain))
 
-	config.rateLimiter.Take()
-
-	_, err := failover.Delete(&config.apiAccess)
+	err := config.client.DeleteFailover(ctx, failover)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -238,9 +238,15 @@ func resourceDnsFailoverDelete(ctx context.Context, d *schema.ResourceData, meta
 func resourceDnsFailoverImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	config := meta.(ClientConfig)
 	RecordId := d.Id()
+	domain := ""
 
-	config.rateLimiter.Take()
-	failoverRead, err := cloudns.Failover{RecordId: RecordId}.Read(&config.apiAccess)
+	// "zone/recordid" is accepted as well, as the webhooks of a failover can't be read without the zone
+	if parts := strings.Split(d.Id(), "/"); len(parts) == 2 {
+		domain = parts[0]
+		RecordId = parts[1]
+	}
+
+	failoverRead, err := config.client.ReadFailover(ctx, apiFailover{Failover: cloudns.Failover{Domain: domain, RecordId: RecordId}})
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +259,11 @@ func resourceDnsFailoverImport(ctx context.Context, d *schema.ResourceData, meta
 	if err != nil {
 		return nil, err
 	}
+
+	err = readFailoverWebhooks(ctx, config, d, failoverRead.Domain, failoverRead.RecordId)
+	if err != nil {
+		return nil, err
+	}
 	d.SetId(RecordId)
 
 	tflog.Debug(ctx, fmt.Sprintf("IMPORT Failover %s", RecordId))
@@ -260,7 +271,7 @@ func resourceDnsFailoverImport(ctx context.Context, d *schema.ResourceData, meta
 	return []*schema.ResourceData{d}, nil
 }
 
-func toApiFailover(d *schema.ResourceData) cloudns.Failover {
+func toApiFailover(d *schema.ResourceData) apiFailover {
 	domain := d.Get("domain").(string)
 	recordId := d.Get("recordid").(string)
 	failoverType := d.Get("checktype").(string)
@@ -275,46 +286,14 @@ func toApiFailover(d *schema.ResourceData) cloudns.Failover {
 	monitoringRegion := d.Get("monitoringregion").(string)
 	checkPeriod := d.Get("checkperiod").(string)
 	notificationMail := d.Get("notificationmail").(string)
-	host := d.Get("host").(string)
-	portStr := d.Get("port").(string)
-	path := d.Get("path").(string)
-	content := d.Get("content").(string)
-	querytype := d.Get("querytype").(string)
-	queryresponse := d.Get("queryresponse").(string)
-	checkRegion := d.Get("checkregion").(string)
-	latencyLimit, err := d.Get("latencyLimit").(string)
-	if err {
-		latencyLimit = ""
-	}
-	timeout := d.Get("timeout").(string)
-	httprequesttype := d.Get("httprequesttype").(string)
-
-	var port cloudns.CustomPort
-	if portInt, err := strconv.Atoi(portStr); err == nil {
-		port = cloudns.CustomPort(portInt)
-	} else {
-		if failoverType == "4" || failoverType == "6" {
-			port = 80
-		}
-
-		if failoverType == "5" || failoverType == "7" {
-			port = 443
-		}
-	}
-
-	var checkSettings = cloudns.CheckSettings{
-		Host:            host,
-		Port:            port,
-		Path:            path,
-		Content:         content,
-		QueryType:       querytype,
-		QueryResponse:   queryresponse,
-		LatencyLimit:    latencyLimit,
-		Timeout:         timeout,
-		HttpRequestType: httprequesttype,
+	// the address read back from ClouDNS is not passed on, it may be one of the cloudns_failover_notification ones
+	if config := d.GetRawConfig(); !config.IsNull() && config.GetAttr("notificationmail").IsNull() {
+		notificationMail = ""
 	}
+	checkRegion := d.Get("checkregion").(string)
+	checkSettings, packetCount := toApiCheckSettings(d)
 
-	return cloudns.Failover{
+	failover := cloudns.Failover{
 		Domain:           domain,
 		RecordId:         recordId,
 		FailoverType:     failoverType,
@@ -332,9 +311,14 @@ func toApiFailover(d *schema.ResourceData) cloudns.Failover {
 		NotificationMail: notificationMail,
 		CheckRegion:      checkRegion,
 	}
+
+	return apiFailover{
+		Failover:    failover,
+		PacketCount: packetCount,
+	}
 }
 
-func updateFailoverState(d *schema.ResourceData, failover *cloudns.Failover) error {
+func updateFailoverState(d *schema.ResourceData, failover *apiFailover) error {
 	err := d.Set("domain", failover.Domain)
 	if err != nil {
 		return err
@@ -405,54 +389,250 @@ func updateFailoverState(d *schema.ResourceData, failover *cloudns.Failover) err
 		return err
 	}
 
-	err = d.Set("host", failover.CheckSettings.Host)
+	err = d.Set("checkregion", failover.CheckRegion)
 	if err != nil {
 		return err
 	}
 
-	if err := d.Set("port", strconv.Itoa(int(failover.CheckSettings.Port))); err != nil {
-		return err
+	return updateCheckSettingsState(d, failover.CheckSettings, failover.PacketCount)
+}
+
+func failoverWebhookSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"url": {
+					Description:      "The URL to call.",
+					Type:             schema.TypeString,
+					Required:         true,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IsURLWithHTTPorHTTPS),
+				},
+				"method": {
+					Description:      fmt.Sprintf("The HTTP method to call the URL with, one of %s. Defaults to POST.", strings.Join(failoverWebhookMethods, ", ")),
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "POST",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(failoverWebhookMethods, false)),
+				},
+				"payload": {
+					Description: "The body sent along with the request.",
+					Type:        schema.TypeString,
+					Optional:    true,
+				},
+			},
+		},
 	}
+}
 
-	err = d.Set("path", failover.CheckSettings.Path)
-	if err != nil {
-		return err
+type failoverWebhook struct {
+	Url     string `json:"url"`
+	Method  string `json:"method"`
+	Payload string `json:"payload"`
+}
+
+// syncFailoverWebhooks sets or removes the webhooks of a failover when their blocks changed
+func syncFailoverWebhooks(ctx context.Context, config ClientConfig, d *schema.ResourceData) error {
+	domain := d.Get("domain").(string)
+	recordId := d.Get("recordid").(string)
+
+	for key, event := range failoverWebhookEvents {
+		if !d.HasChange(key) {
+			continue
+		}
+
+		webhook, isset := toApiFailoverWebhook(d, key)
+		if !isset {
+			tflog.Debug(ctx, fmt.Sprintf("Removing %s webhook of failover #%s", event, recordId))
+
+			err := config.client.DeleteFailoverWebhook(ctx, domain, recordId, event)
+			if err != nil && !errors.Is(err, errNotFound) {
+				return err
+			}
+			continue
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Setting %s webhook of failover #%s", event, recordId))
+
+		err := config.client.SetFailoverWebhook(ctx, domain, recordId, event, webhook)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = d.Set("content", failover.CheckSettings.Content)
-	if err != nil {
-		return err
+	return nil
+}
+
+func readFailoverWebhooks(ctx context.Context, config ClientConfig, d *schema.ResourceData, domain string, recordId string) error {
+	for key, event := range failoverWebhookEvents {
+		webhook, err := config.client.ReadFailoverWebhook(ctx, domain, recordId, event)
+		if err != nil && !errors.Is(err, errNotFound) {
+			return err
+		}
+
+		err = updateFailoverWebhookState(d, key, webhook)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = d.Set("querytype", failover.CheckSettings.QueryType)
-	if err != nil {
-		return err
+	return nil
+}
+
+func toApiFailoverWebhook(d *schema.ResourceData, key string) (failoverWebhook, bool) {
+	blocks := d.Get(key).([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return failoverWebhook{}, false
 	}
 
-	err = d.Set("queryresponse", failover.CheckSettings.QueryResponse)
-	if err != nil {
-		return err
+	block := blocks[0].(map[string]interface{})
+	return failoverWebhook{
+		Url:     block["url"].(string),
+		Method:  block["method"].(string),
+		Payload: block["payload"].(string),
+	}, true
+}
+
+func updateFailoverWebhookState(d *schema.ResourceData, key string, webhook failoverWebhook) error {
+	if webhook.Url == "" {
+		return d.Set(key, nil)
 	}
 
-	err = d.Set("latencylimit", failover.CheckSettings.LatencyLimit)
-	if err != nil {
-		return err
+	method := webhook.Method
+	if method == "" {
+		method = "POST"
 	}
 
-	err = d.Set("timeout", failover.CheckSettings.Timeout)
-	if err != nil {
-		return err
+	return d.Set(key, []interface{}{
+		map[string]interface{}{
+			"url":     webhook.Url,
+			"method":  strings.ToUpper(method),
+			"payload": webhook.Payload,
+		},
+	})
+}
+
+// apiFailover adds the check settings `cloudns-go` does not model yet to cloudns.Failover
+type apiFailover struct {
+	cloudns.Failover
+	PacketCount int
+}
+
+// activateFailover is the request body of the failover endpoints
+type activateFailover struct {
+	cloudns.ActivateFailover
+	PacketCount int `json:"packet_count,omitempty"`
+}
+
+// failoverSettings is the response body of the failover settings endpoint
+type failoverSettings struct {
+	cloudns.FailoverData
+	CheckSettings checkSettings `json:"check_settings"`
+}
+
+func (f apiFailover) request() activateFailover {
+	return activateFailover{
+		ActivateFailover: cloudns.ActivateFailover{
+			Domain:           f.Domain,
+			RecordId:         f.RecordId,
+			FailoverType:     f.FailoverType,
+			DownEventHandler: f.DownEventHandler,
+			UpEventHandler:   f.UpEventHandler,
+			MainIP:           f.MainIP,
+			BackupIp1:        f.BackupIp1,
+			BackupIp2:        f.BackupIp2,
+			BackupIp3:        f.BackupIp3,
+			BackupIp4:        f.BackupIp4,
+			BackupIp5:        f.BackupIp5,
+			MonitoringRegion: f.MonitoringRegion,
+			Host:             f.CheckSettings.Host,
+			Port:             f.CheckSettings.Port,
+			Path:             f.CheckSettings.Path,
+			Content:          f.CheckSettings.Content,
+			QueryType:        f.CheckSettings.QueryType,
+			QueryResponse:    f.CheckSettings.QueryResponse,
+			CheckPeriod:      f.CheckPeriod,
+			NotificationMail: f.NotificationMail,
+			LatencyLimit:     f.CheckSettings.LatencyLimit,
+			Timeout:          f.CheckSettings.Timeout,
+			CheckRegion:      f.CheckRegion,
+			HttpRequestType:  f.CheckSettings.HttpRequestType,
+		},
+		PacketCount: f.PacketCount,
 	}
+}
 
-	err = d.Set("checkregion", failover.CheckRegion)
+// Create activates the failover, the packet count is not supported by cloudns.Failover.Create
+func (f apiFailover) Create(ctx context.Context, conn *apiConn) (apiFailover, error) {
+	return f, conn.request(ctx, "/dns/failover-activate.json", f.request(), nil)
+}
+
+// Update modifies the failover, the packet count is not supported by cloudns.Failover.Update
+func (f apiFailover) Update(ctx context.Context, conn *apiConn) (apiFailover, error) {
+	return f, conn.request(ctx, "/dns/failover-modify.json", f.request(), nil)
+}
+
+// Read fetches the failover settings, the packet count is not returned by cloudns.Failover.Read
+func (f apiFailover) Read(ctx context.Context, conn *apiConn) (apiFailover, error) {
+	var settings failoverSettings
+	err := conn.request(ctx, "/dns/failover-settings.json", map[string]interface{}{
+		"domain-name": f.Domain,
+		"record-id":   f.RecordId,
+	}, &settings)
 	if err != nil {
-		return err
+		return f, err
 	}
 
-	err = d.Set("httprequesttype", failover.CheckSettings.HttpRequestType)
-	if err != nil {
-		return err
+	return f.withSettings(settings), nil
+}
+
+func (f apiFailover) withSettings(settings failoverSettings) apiFailover {
+	f.FailoverType = settings.FailoverType
+	f.DownEventHandler = settings.DownEventHandler
+	f.UpEventHandler = settings.UpEventHandler
+	f.MainIP = settings.MainIP
+	f.BackupIp1 = settings.BackupIp1
+	f.BackupIp2 = settings.BackupIp2
+	f.BackupIp3 = settings.BackupIp3
+	f.BackupIp4 = settings.BackupIp4
+	f.BackupIp5 = settings.BackupIp5
+	f.MonitoringRegion = settings.MonitoringRegion
+	f.CheckPeriod = settings.CheckPeriod
+	f.CheckRegion = settings.CheckRegion
+	f.NotificationMail = settings.NotificationMail
+	f.CheckSettings = settings.CheckSettings.CheckSettings
+	f.PacketCount = int(settings.CheckSettings.PacketCount)
+
+	return f
+}
+
+// resourceDnsFailoverV0 is the schema in which latencylimit and timeout were strings
+func resourceDnsFailoverV0() *schema.Resource {
+	s := resourceDnsFailoverSchema()
+	s["latencylimit"] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	s["timeout"] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	delete(s, "packetcount")
+
+	return &schema.Resource{Schema: s}
+}
+
+func resourceDnsFailoverStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	for _, key := range []string{"latencylimit", "timeout"} {
+		raw, ok := rawState[key].(string)
+		if !ok {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			delete(rawState, key)
+			continue
+		}
+		rawState[key] = value
 	}
 
-	return nil
+	return rawState, nil
 }
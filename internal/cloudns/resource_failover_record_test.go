@@ -0,0 +1,454 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestToApiFailoverCheckSettings(t *testing.T) {
+	cases := map[string]struct {
+		raw         map[string]interface{}
+		expected    cloudns.CheckSettings
+		packetCount int
+	}{
+		"ping with tunables": {
+			raw: map[string]interface{}{
+				"checktype":    "1",
+				"latencylimit": 150,
+				"timeout":      3,
+				"packetcount":  5,
+			},
+			expected:    cloudns.CheckSettings{LatencyLimit: "150", Timeout: "3"},
+			packetCount: 5,
+		},
+		"ping without tunables": {
+			raw: map[string]interface{}{
+				"checktype": "1",
+			},
+			expected: cloudns.CheckSettings{},
+		},
+		"http with default port": {
+			raw: map[string]interface{}{
+				"checktype":       "4",
+				"host":            "www.example.com",
+				"path":            "/health",
+				"httprequesttype": "HEAD",
+			},
+			expected: cloudns.CheckSettings{Host: "www.example.com", Path: "/health", Port: 80, HttpRequestType: "HEAD"},
+		},
+		"https with custom port": {
+			raw: map[string]interface{}{
+				"checktype": "5",
+				"port":      "8443",
+			},
+			expected: cloudns.CheckSettings{Port: 8443},
+		},
+		"dns": {
+			raw: map[string]interface{}{
+				"checktype":     "8",
+				"querytype":     "A",
+				"queryresponse": "1.2.3.4",
+			},
+			expected: cloudns.CheckSettings{QueryType: "A", QueryResponse: "1.2.3.4"},
+		},
+	}
+
+	for name, c := range cases {
+		c.raw["domain"] = "example.com"
+		c.raw["recordid"] = "123456789"
+		c.raw["mainip"] = "1.2.3.4"
+		d := schema.TestResourceDataRaw(t, resourceDnsFailover().Schema, c.raw)
+
+		failover := toApiFailover(d)
+		if !reflect.DeepEqual(failover.CheckSettings, c.expected) {
+			t.Errorf("%s: bad check settings: %+v expected: %+v", name, failover.CheckSettings, c.expected)
+		}
+		if failover.PacketCount != c.packetCount {
+			t.Errorf("%s: bad packet count: %d expected: %d", name, failover.PacketCount, c.packetCount)
+		}
+	}
+}
+
+func TestFailoverRequestBody(t *testing.T) {
+	failover := apiFailover{
+		Failover: cloudns.Failover{
+			Domain:        "example.com",
+			RecordId:      "123456789",
+			FailoverType:  "1",
+			MainIP:        "1.2.3.4",
+			CheckSettings: cloudns.CheckSettings{LatencyLimit: "150", Timeout: "3"},
+		},
+		PacketCount: 5,
+	}
+
+	payload, err := apiPayload(&cloudns.Apiaccess{Authid: 42, Authpassword: "secret"}, failover.request())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"auth-id":       42.0,
+		"auth-password": "secret",
+		"domain-name":   "example.com",
+		"record-id":     "123456789",
+		"check_type":    "1",
+		"latency_limit": "150",
+		"timeout":       "3",
+		"packet_count":  5.0,
+	}
+	for k, exp := range expected {
+		if body[k] != exp {
+			t.Errorf("bad %#v: %#v expected: %#v", k, body[k], exp)
+		}
+	}
+	if _, isset := body["sub-auth-id"]; isset {
+		t.Errorf("unexpected sub-auth-id in %s", payload)
+	}
+}
+
+func TestFailoverSettingsRoundTrip(t *testing.T) {
+	body := `{
+		"check_type": "1",
+		"down_event_handler": "0",
+		"up_event_handler": "0",
+		"main_ip": "1.2.3.4",
+		"backup_ip_1": "5.6.7.8",
+		"check_settings": {"latency_limit": "150", "timeout": "3", "packet_count": "5"}
+	}`
+
+	var settings failoverSettings
+	if err := json.Unmarshal([]byte(body), &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	read := apiFailover{Failover: cloudns.Failover{Domain: "example.com", RecordId: "123456789"}}.withSettings(settings)
+
+	d := schema.TestResourceDataRaw(t, resourceDnsFailover().Schema, map[string]interface{}{})
+	if err := updateFailoverState(d, &read); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, exp := range map[string]interface{}{
+		"domain":       "example.com",
+		"backupip1":    "5.6.7.8",
+		"latencylimit": 150,
+		"timeout":      3,
+		"packetcount":  5,
+	} {
+		if val := d.Get(k); val != exp {
+			t.Errorf("bad %#v: %#v expected: %#v", k, val, exp)
+		}
+	}
+
+	if roundTrip := toApiFailover(d); !reflect.DeepEqual(roundTrip, read) {
+		t.Errorf("failover changed on round trip: %+v expected: %+v", roundTrip, read)
+	}
+}
+
+func TestFailoverStateUpgradeV0(t *testing.T) {
+	state, err := resourceDnsFailoverStateUpgradeV0(context.Background(), map[string]interface{}{
+		"checktype":    "1",
+		"latencylimit": "",
+		"timeout":      "2",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, isset := state["latencylimit"]; isset {
+		t.Errorf("expected empty latencylimit to be dropped: %+v", state)
+	}
+	if state["timeout"] != 2 {
+		t.Errorf("bad timeout: %#v", state["timeout"])
+	}
+	if state["checktype"] != "1" {
+		t.Errorf("bad checktype: %#v", state["checktype"])
+	}
+}
+
+func TestFailoverPingSettingsValidation(t *testing.T) {
+	s := resourceDnsFailover().Schema
+
+	if diags := s["timeout"].ValidateDiagFunc(6, nil); !diags.HasError() {
+		t.Error("expected timeout above 5 to be rejected")
+	}
+	if diags := s["timeout"].ValidateDiagFunc(0, nil); !diags.HasError() {
+		t.Error("expected timeout below 1 to be rejected")
+	}
+	if diags := s["latencylimit"].ValidateDiagFunc(0, nil); !diags.HasError() {
+		t.Error("expected latencylimit below 1 to be rejected")
+	}
+}
+
+func TestFailoverPingSettingsOnlyForPing(t *testing.T) {
+	cases := map[string]struct {
+		checktype cty.Value
+		rejected  bool
+	}{
+		"ping":    {checktype: cty.StringVal(checkTypePing)},
+		"http":    {checktype: cty.StringVal("4"), rejected: true},
+		"unknown": {checktype: cty.UnknownVal(cty.String)},
+	}
+
+	r := resourceDnsFailover()
+	for name, c := range cases {
+		attributes := map[string]cty.Value{}
+		for attribute, ty := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+			attributes[attribute] = cty.NullVal(ty)
+		}
+		attributes["domain"] = cty.StringVal("example.com")
+		attributes["recordid"] = cty.StringVal("1")
+		attributes["checktype"] = c.checktype
+		attributes["mainip"] = cty.StringVal("1.2.3.4")
+		attributes["timeout"] = cty.NumberIntVal(3)
+
+		// planned the way Terraform plans a new resource, along with its raw configuration
+		raw := cty.ObjectVal(attributes)
+		config := terraform.NewResourceConfigShimmed(raw, r.CoreConfigSchema())
+		_, err := r.SimpleDiff(context.Background(), &terraform.InstanceState{RawConfig: raw}, config, nil)
+		if rejected := err != nil; rejected != c.rejected {
+			t.Errorf("%s: expected timeout to be rejected %t, got %v", name, c.rejected, err)
+		}
+	}
+}
+
+func TestFailoverNotificationMailOnlyWhenConfigured(t *testing.T) {
+	cases := map[string]struct {
+		configured cty.Value
+		expected   string
+	}{
+		// e.g. read back from the notifications cloudns_failover_notification manages
+		"not configured": {configured: cty.NullVal(cty.String), expected: ""},
+		"configured":     {configured: cty.StringVal("oncall@example.com"), expected: "oncall@example.com"},
+	}
+
+	r := resourceDnsFailover()
+	for name, c := range cases {
+		attributes := map[string]cty.Value{}
+		for attribute, ty := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+			attributes[attribute] = cty.NullVal(ty)
+		}
+		attributes["domain"] = cty.StringVal("example.com")
+		attributes["recordid"] = cty.StringVal("1")
+		attributes["checktype"] = cty.StringVal(checkTypePing)
+		attributes["mainip"] = cty.StringVal("1.2.3.4")
+		attributes["notificationmail"] = c.configured
+
+		d := r.Data(&terraform.InstanceState{
+			ID: "1",
+			Attributes: map[string]string{
+				"domain":           "example.com",
+				"recordid":         "1",
+				"checktype":        checkTypePing,
+				"mainip":           "1.2.3.4",
+				"notificationmail": "oncall@example.com",
+			},
+			RawConfig: cty.ObjectVal(attributes),
+		})
+
+		if mail := toApiFailover(d).NotificationMail; mail != c.expected {
+			t.Errorf("%s: expected the notification mail %q to be passed on, got %q", name, c.expected, mail)
+		}
+	}
+
+	if s := r.Schema["notificationmail"]; !s.Optional || !s.Computed {
+		t.Error("expected notificationmail to be kept from ClouDNS when not configured")
+	}
+}
+
+func TestFailoverWebhookRoundTrip(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceDnsFailover().Schema, map[string]interface{}{
+		"domain":    "example.com",
+		"recordid":  "123456789",
+		"checktype": "1",
+		"mainip":    "1.2.3.4",
+		"down_webhook": []interface{}{
+			map[string]interface{}{
+				"url":     "https://hooks.example.com/down",
+				"payload": `{"state":"down"}`,
+			},
+		},
+	})
+
+	down, isset := toApiFailoverWebhook(d, "down_webhook")
+	if !isset {
+		t.Fatal("expected down_webhook to be set")
+	}
+	if down.Url != "https://hooks.example.com/down" || down.Method != "POST" || down.Payload != `{"state":"down"}` {
+		t.Fatalf("bad down webhook: %+v", down)
+	}
+
+	if _, isset := toApiFailoverWebhook(d, "up_webhook"); isset {
+		t.Fatal("expected up_webhook not to be set")
+	}
+
+	if err := updateFailoverWebhookState(d, "up_webhook", failoverWebhook{Url: "https://hooks.example.com/up", Method: "get"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateFailoverWebhookState(d, "down_webhook", failoverWebhook{}); err != nil {
+		t.Fatal(err)
+	}
+
+	up, isset := toApiFailoverWebhook(d, "up_webhook")
+	if !isset || up.Url != "https://hooks.example.com/up" || up.Method != "GET" {
+		t.Fatalf("bad up webhook: %+v", up)
+	}
+
+	if _, isset := toApiFailoverWebhook(d, "down_webhook"); isset {
+		t.Fatal("expected down_webhook to be removed")
+	}
+}
+
+func TestFailoverWebhookValidation(t *testing.T) {
+	webhook := failoverWebhookSchema("test").Elem.(*schema.Resource)
+
+	if diags := webhook.Schema["url"].ValidateDiagFunc("ftp://hooks.example.com", nil); !diags.HasError() {
+		t.Fatal("expected non HTTP(S) URL to be rejected")
+	}
+
+	if diags := webhook.Schema["method"].ValidateDiagFunc("DELETE", nil); !diags.HasError() {
+		t.Fatal("expected DELETE to be rejected")
+	}
+}
+
+func TestFailoverEventHandlerConflictsWithWebhook(t *testing.T) {
+	for _, event := range []string{"down", "up"} {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"domain":               "example.com",
+			"recordid":             "1",
+			"checktype":            "1",
+			"mainip":               "1.2.3.4",
+			event + "eventhandler": "1",
+			event + "_webhook":     []interface{}{map[string]interface{}{"url": "https://hooks.example.com"}},
+		})
+		if diags := resourceDnsFailover().Validate(config); !diags.HasError() || diags[0].Summary != "Conflicting configuration arguments" {
+			t.Errorf("expected %seventhandler and %s_webhook to conflict, got %+v", event, event, diags)
+		}
+	}
+}
+
+func TestFailoverLifecycleAgainstFakeApi(t *testing.T) {
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	config := api.ClientConfig(t)
+	ctx := context.Background()
+
+	record, err := config.client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: "www", Rtype: "A", Record: "1.2.3.4", TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceDnsFailover().Schema, map[string]interface{}{
+		"domain":    "example.com",
+		"recordid":  record.ID,
+		"checktype": "17",
+		"mainip":    "1.2.3.4",
+		"backupip1": "5.6.7.8",
+		"host":      "www.example.com",
+		"port":      "8080",
+		"down_webhook": []interface{}{
+			map[string]interface{}{
+				"url": "https://hooks.example.com/down",
+			},
+		},
+	})
+
+	if diags := resourceDnsFailoverCreate(ctx, d, config); diags.HasError() {
+		t.Fatalf("create: %+v", diags)
+	}
+	if d.Id() != record.ID || d.Get("backupip1") != "5.6.7.8" || d.Get("host") != "www.example.com" || d.Get("port") != "8080" {
+		t.Fatalf("expected the failover settings in state, got %s %+v", d.Id(), d.State())
+	}
+	if webhook, isset := toApiFailoverWebhook(d, "down_webhook"); !isset || webhook.Url != "https://hooks.example.com/down" {
+		t.Fatalf("expected the down webhook in state, got %+v", webhook)
+	}
+
+	if diags := resourceDnsFailoverCreate(ctx, d, config); !diags.HasError() {
+		t.Fatal("expected activating the failover twice to fail")
+	}
+
+	if diags := resourceDnsFailoverDelete(ctx, d, config); diags.HasError() {
+		t.Fatalf("delete: %+v", diags)
+	}
+
+	d.SetId(record.ID)
+	if diags := resourceDnsFailoverRead(ctx, d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected the deactivated failover to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
+
+func TestAccDnsFailover(t *testing.T) {
+	testUuid := uuid.NewString()
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: failover(testUuid, "5.6.7.8"),
+				Check:  checkFailover("5.6.7.8"),
+			},
+			{
+				Config: failover(testUuid, "9.10.11.12"),
+				Check:  checkFailover("9.10.11.12"),
+			},
+		},
+		CheckDestroy: CheckDestroyedRecords,
+	})
+}
+
+func failover(name string, backupIp string) string {
+	return record("A", "failover-record", name, "1.2.3.4") + fmt.Sprintf(`
+resource "cloudns_dns_failover" "some-failover" {
+  domain           = cloudns_dns_record.failover-record.zone
+  recordid         = cloudns_dns_record.failover-record.id
+  checktype        = "1"
+  mainip           = "1.2.3.4"
+  backupip1        = "%s"
+  downeventhandler = "1"
+  upeventhandler   = "1"
+}
+`, backupIp)
+}
+
+func checkFailover(backupIp string) resource.TestCheckFunc {
+	path := "cloudns_dns_failover.some-failover"
+	return resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttrPair(path, "recordid", "cloudns_dns_record.failover-record", "id"),
+		resource.TestCheckResourceAttr(path, "domain", testZone),
+		resource.TestCheckResourceAttr(path, "checktype", "1"),
+		resource.TestCheckResourceAttr(path, "mainip", "1.2.3.4"),
+		resource.TestCheckResourceAttr(path, "backupip1", backupIp),
+	)
+}
+
+func TestFailoverReadNotFound(t *testing.T) {
+	config := ClientConfig{client: newMemoryApi()}
+
+	d := schema.TestResourceDataRaw(t, resourceDnsFailover().Schema, map[string]interface{}{
+		"domain":    "example.com",
+		"recordid":  "123456789",
+		"checktype": "1",
+		"mainip":    "1.2.3.4",
+	})
+	d.SetId("123456789")
+
+	if diags := resourceDnsFailoverRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a failover deactivated outside of Terraform to be removed from state, got %s %+v", d.Id(), diags)
+	}
+}
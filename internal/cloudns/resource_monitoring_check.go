@@ -0,0 +1,282 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceMonitoringCheck() *schema.Resource {
+	return &schema.Resource{
+		Description: "A monitoring check managed by ClouDNS, independent of any DNS record.",
+
+		CreateContext: resourceMonitoringCheckCreate,
+		ReadContext:   resourceMonitoringCheckRead,
+		UpdateContext: resourceMonitoringCheckUpdate,
+		DeleteContext: resourceMonitoringCheckDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: resourceCheckValidate,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: resourceMonitoringCheckSchema(),
+	}
+}
+
+func resourceMonitoringCheckSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Description:      "The name of the monitoring check.",
+			Type:             schema.TypeString,
+			Required:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+		},
+		"checktype": {
+			Description: "Monitoring check type, the same as for failovers (e.g. 1 for Ping).",
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+		"ip": {
+			Description: "IP address which will be monitored.",
+			Type:        schema.TypeString,
+			Required:    true,
+		},
+		"status": {
+			Description: "The current status of the check as reported by ClouDNS.",
+			Type:        schema.TypeString,
+			Computed:    true,
+		},
+	}
+
+	for k, v := range checkSchema() {
+		s[k] = v
+	}
+
+	return s
+}
+
+// monitoringCheck is the representation of a monitoring check in the ClouDNS API
+type monitoringCheck struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name"`
+	CheckType        string        `json:"check_type"`
+	IP               string        `json:"ip"`
+	MonitoringRegion string        `json:"monitoring_region,omitempty"`
+	CheckPeriod      string        `json:"check_period,omitempty"`
+	NotificationMail string        `json:"notification_mail,omitempty"`
+	Status           string        `json:"status,omitempty"`
+	CheckSettings    checkSettings `json:"check_settings"`
+}
+
+// monitoringCheckRequest is the request body of the monitoring endpoints, which expect the check settings inline
+type monitoringCheckRequest struct {
+	ID               string `json:"id,omitempty"`
+	Name             string `json:"name"`
+	CheckType        string `json:"check_type"`
+	IP               string `json:"ip"`
+	MonitoringRegion string `json:"monitoring_region,omitempty"`
+	CheckPeriod      string `json:"check_period,omitempty"`
+	NotificationMail string `json:"notification_mail,omitempty"`
+	cloudns.CheckSettings
+	PacketCount int `json:"packet_count,omitempty"`
+}
+
+func (c monitoringCheck) request() monitoringCheckRequest {
+	return monitoringCheckRequest{
+		ID:               c.ID,
+		Name:             c.Name,
+		CheckType:        c.CheckType,
+		IP:               c.IP,
+		MonitoringRegion: c.MonitoringRegion,
+		CheckPeriod:      c.CheckPeriod,
+		NotificationMail: c.NotificationMail,
+		CheckSettings:    c.CheckSettings.CheckSettings,
+		PacketCount:      int(c.CheckSettings.PacketCount),
+	}
+}
+
+func (c monitoringCheck) Create(ctx context.Context, conn *apiConn) (monitoringCheck, error) {
+	var created struct {
+		Data struct {
+			ID apiInt `json:"id"`
+		} `json:"data"`
+	}
+
+	err := conn.request(ctx, "/monitoring/create.json", c.request(), &created)
+	if err != nil {
+		return c, err
+	}
+
+	if created.Data.ID == 0 {
+		return c, fmt.Errorf("no ID returned for monitoring check %s", c.Name)
+	}
+
+	c.ID = strconv.Itoa(int(created.Data.ID))
+	return c, nil
+}
+
+func (c monitoringCheck) Read(ctx context.Context, conn *apiConn) (monitoringCheck, error) {
+	var read monitoringCheck
+	err := conn.request(ctx, "/monitoring/get.json", map[string]interface{}{"id": c.ID}, &read)
+	if err != nil {
+		return c, err
+	}
+
+	if read.ID == "" {
+		return c, fmt.Errorf("monitoring check %s: %w", c.ID, errNotFound)
+	}
+
+	return read, nil
+}
+
+func (c monitoringCheck) Update(ctx context.Context, conn *apiConn) (monitoringCheck, error) {
+	return c, conn.request(ctx, "/monitoring/update.json", c.request(), nil)
+}
+
+func (c monitoringCheck) Delete(ctx context.Context, conn *apiConn) (monitoringCheck, error) {
+	return c, conn.request(ctx, "/monitoring/delete.json", map[string]interface{}{"id": c.ID}, nil)
+}
+
+func resourceMonitoringCheckCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	check := toApiMonitoringCheck(d)
+
+	tflog.Debug(ctx, fmt.Sprintf("CREATE Monitoring check %s (type %s) for %s", check.Name, check.CheckType, check.IP))
+
+	created, err := config.client.CreateMonitoringCheck(ctx, check)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(created.ID)
+
+	return resourceMonitoringCheckRead(ctx, d, meta)
+}
+
+func resourceMonitoringCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	check := toApiMonitoringCheck(d)
+
+	tflog.Debug(ctx, fmt.Sprintf("READ Monitoring check #%s", check.ID))
+
+	read, err := config.client.ReadMonitoringCheck(ctx, check)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			tflog.Warn(ctx, fmt.Sprintf("Monitoring check not found: %s. Removing from state.", check.ID))
+			d.SetId("")
+			return nil
+		}
+
+		return diag.FromErr(err)
+	}
+
+	err = updateMonitoringCheckState(d, &read)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceMonitoringCheckUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	check := toApiMonitoringCheck(d)
+
+	tflog.Debug(ctx, fmt.Sprintf("UPDATE Monitoring check #%s", check.ID))
+
+	_, err := config.client.UpdateMonitoringCheck(ctx, check)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceMonitoringCheckRead(ctx, d, meta)
+}
+
+func resourceMonitoringCheckDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(ClientConfig)
+	check := toApiMonitoringCheck(d)
+
+	tflog.Debug(ctx, fmt.Sprintf("DELETE Monitoring check #%s", check.ID))
+
+	err := config.client.DeleteMonitoringCheck(ctx, check)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func toApiMonitoringCheck(d *schema.ResourceData) monitoringCheck {
+	settings, packetCount := toApiCheckSettings(d)
+
+	return monitoringCheck{
+		ID:               d.Id(),
+		Name:             d.Get("name").(string),
+		CheckType:        d.Get("checktype").(string),
+		IP:               d.Get("ip").(string),
+		MonitoringRegion: d.Get("monitoringregion").(string),
+		CheckPeriod:      d.Get("checkperiod").(string),
+		NotificationMail: d.Get("notificationmail").(string),
+		CheckSettings: checkSettings{
+			CheckSettings: settings,
+			PacketCount:   apiInt(packetCount),
+		},
+	}
+}
+
+func updateMonitoringCheckState(d *schema.ResourceData, check *monitoringCheck) error {
+	err := d.Set("name", check.Name)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("checktype", check.CheckType)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("ip", check.IP)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("status", check.Status)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("monitoringregion", check.MonitoringRegion)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("checkperiod", check.CheckPeriod)
+	if err != nil {
+		return err
+	}
+
+	err = d.Set("notificationmail", check.NotificationMail)
+	if err != nil {
+		return err
+	}
+
+	return updateCheckSettingsState(d, check.CheckSettings.CheckSettings, int(check.CheckSettings.PacketCount))
+}
@@ -0,0 +1,109 @@
+package cloudns
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMonitoringCheckRequestBody(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceMonitoringCheck().Schema, map[string]interface{}{
+		"name":            "www",
+		"checktype":       "5",
+		"ip":              "1.2.3.4",
+		"host":            "www.example.com",
+		"path":            "/health",
+		"httprequesttype": "HEAD",
+		"checkperiod":     "60",
+	})
+	d.SetId("42")
+
+	payload, err := apiPayload(&cloudns.Apiaccess{Subauthid: 7, Authpassword: "secret"}, toApiMonitoringCheck(d).request())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"sub-auth-id":       7.0,
+		"id":                "42",
+		"name":              "www",
+		"check_type":        "5",
+		"ip":                "1.2.3.4",
+		"host":              "www.example.com",
+		"port":              443.0,
+		"path":              "/health",
+		"http_request_type": "HEAD",
+		"check_period":      "60",
+	}
+	for k, exp := range expected {
+		if body[k] != exp {
+			t.Errorf("bad %#v: %#v expected: %#v", k, body[k], exp)
+		}
+	}
+	for _, k := range []string{"auth-id", "check_settings", "packet_count", "latency_limit"} {
+		if _, isset := body[k]; isset {
+			t.Errorf("unexpected %s in %s", k, payload)
+		}
+	}
+}
+
+func TestMonitoringCheckRoundTrip(t *testing.T) {
+	body := `{
+		"id": "42",
+		"name": "gateway",
+		"check_type": "1",
+		"ip": "1.2.3.4",
+		"status": "UP",
+		"check_settings": {"latency_limit": "150", "timeout": "2", "packet_count": 3}
+	}`
+
+	var read monitoringCheck
+	if err := json.Unmarshal([]byte(body), &read); err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceMonitoringCheck().Schema, map[string]interface{}{})
+	d.SetId(read.ID)
+	if err := updateMonitoringCheckState(d, &read); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := d.Get("status"); status != "UP" {
+		t.Errorf("bad status: %#v", status)
+	}
+
+	roundTrip := toApiMonitoringCheck(d)
+	read.Status = ""
+	if !reflect.DeepEqual(roundTrip, read) {
+		t.Errorf("monitoring check changed on round trip: %+v expected: %+v", roundTrip, read)
+	}
+}
+
+func TestMonitoringCheckNotFound(t *testing.T) {
+	config := ClientConfig{client: newMemoryApi()}
+
+	d := schema.TestResourceDataRaw(t, resourceMonitoringCheck().Schema, map[string]interface{}{
+		"name":      "www",
+		"checktype": "1",
+		"ip":        "1.2.3.4",
+	})
+	d.SetId("123456789")
+
+	if diags := resourceMonitoringCheckRead(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a check deleted outside of Terraform to be removed from state, got %s %+v", d.Id(), diags)
+	}
+
+	d.SetId("123456789")
+	if diags := resourceMonitoringCheckDelete(context.Background(), d, config); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected deleting a check which is gone to succeed, got %s %+v", d.Id(), diags)
+	}
+}
@@ -0,0 +1,169 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	defaultMaxRetries   = 5
+	defaultRetryMaxWait = 60
+	retryBaseDelay      = 500 * time.Millisecond
+	retryMaxDelay       = 10 * time.Second
+)
+
+// retryPolicy decides whether and when a failed API call is attempted again
+type retryPolicy struct {
+	// maxRetries is the number of attempts made after the first one, 0 disables retries
+	maxRetries int
+	// maxElapsed caps the total time spent on a single call, including all attempts
+	maxElapsed time.Duration
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	// retryable tells the errors worth another attempt, isRetryableError when nil
+	retryable func(error) bool
+}
+
+func newRetryPolicy(maxRetries int, maxElapsed time.Duration) retryPolicy {
+	return retryPolicy{
+		maxRetries: maxRetries,
+		maxElapsed: maxElapsed,
+		baseDelay:  retryBaseDelay,
+		maxDelay:   retryMaxDelay,
+	}
+}
+
+// nonIdempotent returns the policy for calls which must not be repeated once ClouDNS processed them, like adding
+// a record or a notification: they are only retried when the request provably never reached ClouDNS
+func (p retryPolicy) nonIdempotent() retryPolicy {
+	p.retryable = isUnprocessedError
+	return p
+}
+
+// run calls fn until it succeeds, fails permanently, ctx is done or the policy gives up
+func (p retryPolicy) run(ctx context.Context, op string, fn func() error) error {
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		// only the context of the caller ends the retries, a request timing out wraps a deadline as well
+		if err == nil || ctx.Err() != nil || !p.isRetryable(err) || attempt >= p.maxRetries {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if p.maxElapsed > 0 && time.Since(start)+delay > p.maxElapsed {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		tflog.Warn(ctx, "Retrying ClouDNS API call", map[string]interface{}{
+			"operation": op,
+			"attempt":   attempt + 1,
+			"delay":     delay.String(),
+			"error":     err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (p retryPolicy) isRetryable(err error) bool {
+	if p.retryable != nil {
+		return p.retryable(err)
+	}
+	return isRetryableError(err)
+}
+
+// isThrottlingError tells whether ClouDNS rejected a call because of the rate it is called at
+func isThrottlingError(err error) bool {
+	var httpErr apiHttpError
+	return errors.Is(err, errRateLimited) || (errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests)
+}
+
+// backoff returns the delay before the retry following attempt: exponential with full jitter
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.maxDelay
+	if attempt < 30 {
+		if exp := p.baseDelay << attempt; exp > 0 && exp < ceiling {
+			ceiling = exp
+		}
+	}
+
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+// isUnprocessedError tells failures of requests ClouDNS did not act on: throttled ones and those which could not
+// connect. A timeout, a dropped connection or a server error may come after the request was processed.
+func isUnprocessedError(err error) bool {
+	if isThrottlingError(err) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// apiHttpError is returned when ClouDNS answers with an HTTP status other than 200
+type apiHttpError struct {
+	StatusCode int
+}
+
+func (e apiHttpError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// isRetryableError tells throttling, server side and network failures apart from permanent ones
+// like validation or authentication errors, which fail the same way on every attempt.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, errRateLimited) || errors.Is(err, errUnavailable) {
+		return true
+	}
+
+	for _, permanent := range []error{errNotFound, errAuth, errUserDisabled, errValidation, errIpNotAllowed, errZoneLimit} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+
+	var httpErr apiHttpError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// connection refused, reset and the like
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	return false
+}
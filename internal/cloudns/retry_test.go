@@ -0,0 +1,129 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	cases := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"throttled":          {toApiError(apiStatusError("Too many requests.")), true},
+		"unavailable":        {toApiError(apiStatusError("Service temporarily unavailable.")), true},
+		"http 429":           {apiHttpError{StatusCode: 429}, true},
+		"http 502":           {fmt.Errorf("calling /dns/records.json: %w", apiHttpError{StatusCode: 502}), true},
+		"http 404":           {apiHttpError{StatusCode: 404}, false},
+		"connection refused": {&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		"invalid record":     {toApiError(apiStatusError("Invalid record type.")), false},
+		"bad credentials":    {toApiError(apiStatusError("Invalid authentication, incorrect auth-id or auth-password.")), false},
+		"cancelled":          {context.Canceled, false},
+		"request timeout":    {fmt.Errorf("calling /dns/records.json: %w", context.DeadlineExceeded), true},
+	}
+
+	for name, c := range cases {
+		if retryable := isRetryableError(c.err); retryable != c.retryable {
+			t.Errorf("%s: expected retryable to be %t, got %t", name, c.retryable, retryable)
+		}
+	}
+}
+
+func TestIsUnprocessedError(t *testing.T) {
+	cases := map[string]struct {
+		err         error
+		unprocessed bool
+	}{
+		"throttled":          {toApiError(apiStatusError("Too many requests.")), true},
+		"http 429":           {apiHttpError{StatusCode: 429}, true},
+		"connection refused": {&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		"connection reset":   {&net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, false},
+		"http 502":           {apiHttpError{StatusCode: 502}, false},
+		"request timeout":    {context.DeadlineExceeded, false},
+		"eof":                {io.EOF, false},
+	}
+
+	for name, c := range cases {
+		if unprocessed := isUnprocessedError(c.err); unprocessed != c.unprocessed {
+			t.Errorf("%s: expected unprocessed to be %t, got %t", name, c.unprocessed, unprocessed)
+		}
+	}
+}
+
+func TestRetryPolicyRetriesTransientErrors(t *testing.T) {
+	policy := retryPolicy{maxRetries: 3, maxElapsed: time.Second, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+	attempts := 0
+	err := policy.run(context.Background(), "test", func() error {
+		attempts++
+		if attempts < 3 {
+			return apiHttpError{StatusCode: 503}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyStopsOnPermanentErrors(t *testing.T) {
+	policy := retryPolicy{maxRetries: 3, maxElapsed: time.Second, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+	attempts := 0
+	permanent := errors.New("Invalid record type.")
+	err := policy.run(context.Background(), "test", func() error {
+		attempts++
+		return permanent
+	})
+	if err != permanent {
+		t.Fatalf("expected the permanent error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	policy := retryPolicy{maxRetries: 2, maxElapsed: time.Second, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+	attempts := 0
+	err := policy.run(context.Background(), "test", func() error {
+		attempts++
+		return apiHttpError{StatusCode: 503}
+	})
+	if !errors.As(err, &apiHttpError{}) {
+		t.Fatalf("expected the last error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+
+	policy = retryPolicy{maxRetries: 10, maxElapsed: time.Millisecond, baseDelay: time.Second, maxDelay: time.Second}
+	attempts = 0
+	err = policy.run(context.Background(), "test", func() error {
+		attempts++
+		return apiHttpError{StatusCode: 503}
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected to give up before waiting beyond the maximum, got %d attempts: %v", attempts, err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := newRetryPolicy(defaultMaxRetries, time.Minute)
+
+	for attempt := 0; attempt < 64; attempt++ {
+		delay := policy.backoff(attempt)
+		if delay <= 0 || delay > retryMaxDelay {
+			t.Fatalf("attempt %d: delay %s out of bounds", attempt, delay)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/ClouDNS/cloudns-go"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestMain runs the sweepers instead of the tests with `go test ./internal/cloudns -sweep=all`
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// sweepUuid matches the names testAccUuid gives the records of acceptance tests
+var sweepUuid = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// sweepZoneName matches the zones acceptance tests create, named after a UUID
+var sweepZoneName = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\.com$`)
+
+func init() {
+	resource.AddTestSweepers("cloudns_dns_failover", &resource.Sweeper{
+		Name: "cloudns_dns_failover",
+		F:    sweeper(sweepFailovers),
+	})
+
+	resource.AddTestSweepers("cloudns_dynamic_url", &resource.Sweeper{
+		Name: "cloudns_dynamic_url",
+		F:    sweeper(sweepDynamicUrls),
+	})
+
+	resource.AddTestSweepers("cloudns_dns_record", &resource.Sweeper{
+		Name:         "cloudns_dns_record",
+		F:            sweeper(sweepRecords),
+		Dependencies: []string{"cloudns_dns_failover", "cloudns_dynamic_url"},
+	})
+
+	resource.AddTestSweepers("cloudns_dns_zone", &resource.Sweeper{
+		Name: "cloudns_dns_zone",
+		F:    sweeper(sweepZones),
+	})
+}
+
+// sweeper runs sweep against the account and the zone the acceptance tests are configured with. The region
+// sweepers are given has no meaning for ClouDNS.
+func sweeper(sweep func(ctx context.Context, client cloudnsApi, zone string) error) func(region string) error {
+	return func(region string) error {
+		ctx := context.Background()
+
+		provider := New()()
+		if diags := provider.Configure(ctx, terraform.NewResourceConfigRaw(nil)); diags.HasError() {
+			return fmt.Errorf("cannot configure the provider: %+v", diags)
+		}
+
+		return sweep(ctx, provider.Meta().(ClientConfig).client, os.Getenv(EnvVarAcceptanceTestsZone))
+	}
+}
+
+// sweepRecordsOf returns the records acceptance tests left in the zone
+func sweepRecordsOf(ctx context.Context, client cloudnsApi, zone string) ([]cloudns.Record, error) {
+	if zone == "" {
+		log.Printf("[WARN] %s is not set, there are no records to sweep", EnvVarAcceptanceTestsZone)
+		return nil, nil
+	}
+
+	records, err := client.ListRecords(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the records of %s: %v", zone, err)
+	}
+
+	var swept []cloudns.Record
+	for _, record := range records {
+		if sweepUuid.MatchString(record.Host) {
+			swept = append(swept, record)
+		}
+	}
+	return swept, nil
+}
+
+func sweepFailovers(ctx context.Context, client cloudnsApi, zone string) error {
+	records, err := sweepRecordsOf(ctx, client, zone)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, record := range records {
+		failover := apiFailover{}
+		failover.Domain = zone
+		failover.RecordId = record.ID
+
+		if _, err := client.ReadFailover(ctx, failover); errors.Is(err, errNotFound) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read the failover of %s.%s: %v", record.Host, zone, err))
+			continue
+		}
+
+		log.Printf("[INFO] deactivating the failover of %s.%s", record.Host, zone)
+		if err := client.DeleteFailover(ctx, failover); err != nil {
+			errs = append(errs, fmt.Errorf("cannot deactivate the failover of %s.%s: %v", record.Host, zone, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sweepDynamicUrls(ctx context.Context, client cloudnsApi, zone string) error {
+	records, err := sweepRecordsOf(ctx, client, zone)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, record := range records {
+		if record.Rtype != "A" && record.Rtype != "AAAA" {
+			continue
+		}
+
+		dynUrl := cloudns.DynamicUrl{Domain: zone, RecordId: record.ID}
+		if _, err := client.ReadDynamicUrl(ctx, dynUrl); errors.Is(err, errNotFound) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("cannot read the dynamic URL of %s.%s: %v", record.Host, zone, err))
+			continue
+		}
+
+		log.Printf("[INFO] disabling the dynamic URL of %s.%s", record.Host, zone)
+		if err := client.DeleteDynamicUrl(ctx, dynUrl); err != nil {
+			errs = append(errs, fmt.Errorf("cannot disable the dynamic URL of %s.%s: %v", record.Host, zone, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sweepRecords(ctx context.Context, client cloudnsApi, zone string) error {
+	records, err := sweepRecordsOf(ctx, client, zone)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, record := range records {
+		log.Printf("[INFO] deleting the %s record %s.%s", record.Rtype, record.Host, zone)
+		if _, err := client.DeleteRecord(ctx, record); err != nil && !errors.Is(err, errNotFound) {
+			errs = append(errs, fmt.Errorf("cannot delete the record %s.%s: %v", record.Host, zone, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sweepZones deletes the zones acceptance tests left in the account, the zone records are tested in is kept
+func sweepZones(ctx context.Context, client cloudnsApi, zone string) error {
+	zones, err := client.ListZones(ctx, ".com")
+	if err != nil {
+		return fmt.Errorf("cannot list zones: %v", err)
+	}
+
+	var errs []error
+	for _, z := range zones {
+		if !sweepZoneName.MatchString(z.Domain) || z.Domain == zone {
+			continue
+		}
+
+		log.Printf("[INFO] deleting the zone %s", z.Domain)
+		if _, err := client.DeleteZone(ctx, z); err != nil && !errors.Is(err, errNotFound) {
+			errs = append(errs, fmt.Errorf("cannot delete the zone %s: %v", z.Domain, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func TestSweepers(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeApi(t)
+	api.AddZone("example.com", "master")
+	api.AddZone("0f8fad5b-d9cb-469f-a165-70867728950e.com", "master")
+	api.AddZone("example-0f8fad5b.com", "master")
+	client := api.ClientConfig(t).client
+
+	var kept cloudns.Record
+	for _, host := range []string{"www", "7c9e6679-7425-40de-944b-e07fc1f90ae7"} {
+		record, err := client.CreateRecord(ctx, cloudns.Record{Domain: "example.com", Host: host, Rtype: "A", Record: "1.2.3.4", TTL: 60})
+		if err != nil {
+			t.Fatal(err)
+		}
+		record.Domain = "example.com"
+
+		failover := apiFailover{}
+		failover.Domain = "example.com"
+		failover.RecordId = record.ID
+		failover.FailoverType = checkTypePing
+		failover.MainIP = "1.2.3.4"
+		if _, err := client.CreateFailover(ctx, failover); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.ReadOrCreateDynamicUrl(ctx, cloudns.DynamicUrl{Domain: "example.com", RecordId: record.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if host == "www" {
+			kept = record
+		}
+	}
+
+	for _, sweep := range []func(ctx context.Context, client cloudnsApi, zone string) error{
+		sweepFailovers, sweepDynamicUrls, sweepRecords, sweepZones,
+	} {
+		if err := sweep(ctx, client, "example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var hosts []string
+	for _, record := range api.Records("example.com") {
+		if record.str("type") == "A" {
+			hosts = append(hosts, record.str("host"))
+		}
+	}
+	if !reflect.DeepEqual(hosts, []string{kept.Host}) {
+		t.Fatalf("expected only %s to be kept, got %v", kept.Host, hosts)
+	}
+	failover := apiFailover{}
+	failover.Domain = "example.com"
+	failover.RecordId = kept.ID
+	if _, err := client.ReadFailover(ctx, failover); err != nil {
+		t.Errorf("expected the failover of %s to be kept, got %v", kept.Host, err)
+	}
+	if _, err := client.ReadDynamicUrl(ctx, cloudns.DynamicUrl{Domain: "example.com", RecordId: kept.ID}); err != nil {
+		t.Errorf("expected the dynamic URL of %s to be kept, got %v", kept.Host, err)
+	}
+	if _, err := client.ReadZone(ctx, cloudns.Zone{Domain: "0f8fad5b-d9cb-469f-a165-70867728950e.com"}); !errors.Is(err, errNotFound) {
+		t.Errorf("expected the test zone to be deleted, got %v", err)
+	}
+	for _, domain := range []string{"example.com", "example-0f8fad5b.com"} {
+		if _, err := client.ReadZone(ctx, cloudns.Zone{Domain: domain}); err != nil {
+			t.Errorf("expected %s to be kept, got %v", domain, err)
+		}
+	}
+}
+
+func TestSweepersSkipWithoutZone(t *testing.T) {
+	api := newMemoryApi()
+	for _, sweep := range []func(ctx context.Context, client cloudnsApi, zone string) error{
+		sweepFailovers, sweepDynamicUrls, sweepRecords,
+	} {
+		if err := sweep(context.Background(), api, ""); err != nil {
+			t.Fatalf("expected nothing to sweep without a zone, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cloudns
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+// zoneCacheTTL bounds how long a listing is reused, so a long apply still notices changes made outside Terraform
+const zoneCacheTTL = time.Minute
+
+// zoneCache keeps the record listings of every zone read during a plan or apply, e.g. the NS records of
+// a zone are read by the zone and by every record in it. Concurrent requests for the same listing share
+// a single API call and every write to a zone drops all of its listings.
+type zoneCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[zoneCacheKey]*zoneCacheEntry
+}
+
+// zoneCacheKey is a listing of a zone, either complete or filtered on the ClouDNS side
+type zoneCacheKey struct {
+	domain string
+	filter recordFilter
+}
+
+type zoneCacheEntry struct {
+	// ready is closed once records and err are set
+	ready   chan struct{}
+	records []cloudns.Record
+	err     error
+	fetched time.Time
+}
+
+func newZoneCache(ttl time.Duration) *zoneCache {
+	return &zoneCache{
+		ttl:     ttl,
+		entries: map[zoneCacheKey]*zoneCacheEntry{},
+	}
+}
+
+// get returns the cached listing of domain matching filter, calling fetch if there is none or it expired
+func (c *zoneCache) get(ctx context.Context, domain string, filter recordFilter, fetch func() ([]cloudns.Record, error)) ([]cloudns.Record, error) {
+	key := zoneCacheKey{domain: domain, filter: filter}
+
+	c.mu.Lock()
+	entry, isset := c.entries[key]
+	if isset && c.expired(entry) {
+		isset = false
+	}
+
+	if !isset {
+		entry = &zoneCacheEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		entry.records, entry.err = fetch()
+		entry.fetched = time.Now()
+		close(entry.ready)
+
+		// failures are not cached, the next caller tries again
+		if entry.err != nil {
+			c.drop(key, entry)
+		}
+
+		return slices.Clone(entry.records), entry.err
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return slices.Clone(entry.records), entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate drops the listings of domain, a listing being fetched concurrently is not stored either
+func (c *zoneCache) invalidate(domain string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.domain == domain {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// expired must be called with mu held
+func (c *zoneCache) expired(entry *zoneCacheEntry) bool {
+	select {
+	case <-entry.ready:
+		return time.Since(entry.fetched) > c.ttl
+	default:
+		// still being fetched
+		return false
+	}
+}
+
+func (c *zoneCache) drop(key zoneCacheKey, entry *zoneCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,137 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ClouDNS/cloudns-go"
+)
+
+func TestZoneCacheCoalescesConcurrentReads(t *testing.T) {
+	cache := newZoneCache(time.Minute)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func() ([]cloudns.Record, error) {
+		fetches.Add(1)
+		<-release
+		return []cloudns.Record{{ID: "1", Domain: "example.com"}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			records, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch)
+			if err != nil || len(records) != 1 {
+				t.Errorf("bad listing: %+v %v", records, err)
+			}
+		}()
+	}
+
+	// give the readers a chance to queue up behind the first fetch
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected a single fetch, got %d", n)
+	}
+}
+
+func TestZoneCacheInvalidate(t *testing.T) {
+	cache := newZoneCache(time.Minute)
+
+	fetches := 0
+	fetch := func() ([]cloudns.Record, error) {
+		fetches++
+		return []cloudns.Record{{ID: "1"}}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("expected a single fetch, got %d", fetches)
+	}
+
+	cache.invalidate("example.com")
+	if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Fatalf("expected a fetch after invalidation, got %d", fetches)
+	}
+
+	// other zones are not affected
+	cache.invalidate("example.org")
+	if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Fatalf("expected the listing to be reused, got %d fetches", fetches)
+	}
+}
+
+func TestZoneCacheExpiryAndErrors(t *testing.T) {
+	cache := newZoneCache(0)
+
+	fetches := 0
+	failure := errors.New("failure")
+	fetch := func() ([]cloudns.Record, error) {
+		fetches++
+		if fetches == 1 {
+			return nil, failure
+		}
+		return []cloudns.Record{{ID: "1"}}, nil
+	}
+
+	if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != failure {
+		t.Fatalf("expected the fetch error, got %v", err)
+	}
+	if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != nil {
+		t.Fatalf("expected the error not to be cached, got %v", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if _, err := cache.get(context.Background(), "example.com", recordFilter{}, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 3 {
+		t.Fatalf("expected the expired listing to be fetched again, got %d fetches", fetches)
+	}
+}
+
+func TestZoneCacheInvalidateFilteredListings(t *testing.T) {
+	cache := newZoneCache(time.Minute)
+
+	fetches := 0
+	fetch := func() ([]cloudns.Record, error) {
+		fetches++
+		return nil, nil
+	}
+
+	filters := []recordFilter{{}, {rtype: "NS"}, {host: "www", rtype: "A"}}
+	for _, filter := range filters {
+		cache.get(context.Background(), "example.com", filter, fetch)
+		cache.get(context.Background(), "example.com", filter, fetch)
+	}
+	if fetches != len(filters) {
+		t.Fatalf("expected a fetch per filter, got %d", fetches)
+	}
+
+	cache.invalidate("example.com")
+	for _, filter := range filters {
+		cache.get(context.Background(), "example.com", filter, fetch)
+	}
+	if fetches != 2*len(filters) {
+		t.Fatalf("expected every listing of the zone to be dropped, got %d fetches", fetches)
+	}
+}
@@ -0,0 +1,40 @@
+package cloudns
+
+import (
+	"context"
+	"sync"
+)
+
+// zoneLocks serializes the writes to a single zone, while writes to different zones run in parallel.
+// ClouDNS bumps the SOA serial on every change and concurrent changes of the same zone collide.
+type zoneLocks struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+func newZoneLocks() *zoneLocks {
+	return &zoneLocks{locks: map[string]chan struct{}{}}
+}
+
+// lock waits until no other write to domain is in flight, or the context is done.
+// A nil zoneLocks does not serialize anything.
+func (l *zoneLocks) lock(ctx context.Context, domain string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	lock, ok := l.locks[domain]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		l.locks[domain] = lock
+	}
+	l.mu.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package cloudns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestZoneLocksSerializeWritesPerZone(t *testing.T) {
+	locks := newZoneLocks()
+
+	var inFlight, maxInFlight atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := locks.lock(context.Background(), "example.com")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+
+			n := inFlight.Add(1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if n := maxInFlight.Load(); n != 1 {
+		t.Fatalf("expected a single write at a time, got %d", n)
+	}
+}
+
+func TestZoneLocksOtherZonesAndCancellation(t *testing.T) {
+	locks := newZoneLocks()
+
+	unlock, err := locks.lock(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := locks.lock(context.Background(), "example.org")
+	if err != nil {
+		t.Fatalf("expected another zone not to wait, got %v", err)
+	}
+	other()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := locks.lock(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to be aborted, got %v", err)
+	}
+
+	unlock()
+	unlock, err = locks.lock(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	var disabled *zoneLocks
+	for i := 0; i < 2; i++ {
+		if _, err := disabled.lock(context.Background(), "example.com"); err != nil {
+			t.Fatalf("expected writes not to be serialized, got %v", err)
+		}
+	}
+}